@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+func listAliases(client KMSActionsAPI) (map[string][]string, error) {
+	/*
+	Function that maps the aliases in the current AWS account/region to the keys they point to.
+
+	Aliases that are not associated with a key are skipped.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:return: A map of key ID to the alias names of the key, or an error from AWS
+	*/
+
+	aliases := map[string][]string{}
+	paginator := kms.NewListAliasesPaginator(client, &kms.ListAliasesInput{})
+
+	for paginator.HasMorePages() {
+		var resp *kms.ListAliasesOutput
+		err := withRetry(func() (err error) {
+			resp, err = paginator.NextPage(context.TODO())
+			return err
+		})
+
+		if err != nil {
+			return aliases, err
+		}
+
+		for _, el := range resp.Aliases {
+			if el.TargetKeyId == nil {
+				continue
+			}
+			aliases[*el.TargetKeyId] = append(aliases[*el.TargetKeyId], aws.ToString(el.AliasName))
+		}
+	}
+	return aliases, nil
+}
+
+func matchAny(names []string, patterns []string) bool {
+	/*
+	Function that checks whether any alias name matches any of the glob patterns.
+
+	:param names: The alias names of a key
+	:param patterns: The glob patterns, such as 'alias/payments-*'
+	:return: A bool that is true when a name matches a pattern
+	*/
+
+	for _, name := range names {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inScope(names []string, cfg Config) bool {
+	/*
+	Function that checks whether a key is in the scope of the sweep.
+
+	A key is in scope when no include patterns are set or one of its aliases
+	matches one, and none of its aliases matches an exclude pattern. A key
+	without aliases is only in scope when no include patterns are set.
+
+	:param names: The alias names of a key
+	:param cfg: The run settings, used for the include and exclude patterns
+	:return: A bool that is true when the key is in scope
+	*/
+
+	if len(cfg.IncludeAliases) > 0 && !matchAny(names, cfg.IncludeAliases) {
+		return false
+	}
+	return !matchAny(names, cfg.ExcludeAliases)
+}
+
+func scopeKeys(keys []types.KeyListEntry, aliases map[string][]string, cfg Config) ([]types.KeyListEntry, []UnownedKey) {
+	/*
+	Function that filters the keys to the ones in scope and finds the keys without an alias.
+
+	:param keys: A slice containing the key data for the keys in the AWS account/region
+	:param aliases: A map of key ID to the alias names of the key
+	:param cfg: The run settings, used for the include and exclude patterns
+	:return: A slice of the keys in scope, keeping the order of keys, and the keys that have no alias
+	*/
+
+	var scoped []types.KeyListEntry
+	unowned := []UnownedKey{}
+
+	for _, el := range keys {
+		keyId := aws.ToString(el.KeyId)
+
+		// the key from an event can be given as an ARN
+		if i := strings.LastIndex(keyId, "/"); i >= 0 {
+			keyId = keyId[i+1:]
+		}
+
+		names := aliases[keyId]
+		if len(names) == 0 {
+			unowned = append(unowned, UnownedKey{KeyId: keyId, Arn: aws.ToString(el.KeyArn)})
+		}
+
+		if inScope(names, cfg) {
+			scoped = append(scoped, el)
+		}
+	}
+	return scoped, unowned
+}
+
+func applyScope(client KMSActionsAPI, keys []types.KeyListEntry, cfg Config, report *Report) ([]types.KeyListEntry, bool) {
+	/*
+	Function that limits the keys to the alias scope and records the unowned keys in the report.
+
+	When the aliases cannot be listed and include or exclude patterns are set,
+	no keys are checked so keys outside the scope are never changed.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param keys: A slice containing the key data for the keys to check
+	:param cfg: The run settings, used for the include and exclude patterns
+	:param report: The report the out of scope count and unowned keys are added to
+	:return: A slice of the keys in scope, and a bool that is false when the keys cannot be scoped
+	*/
+
+	aliases, err := listAliases(client)
+	if err != nil {
+		log.Println(err)
+
+		if len(cfg.IncludeAliases) > 0 || len(cfg.ExcludeAliases) > 0 {
+			report.Error = "listing aliases failed, no keys checked: " + err.Error()
+			return nil, false
+		}
+		return keys, true
+	}
+
+	scoped, unowned := scopeKeys(keys, aliases, cfg)
+	report.OutOfScope = len(keys) - len(scoped)
+	report.Unowned = unowned
+
+	if len(unowned) > 0 {
+		log.Printf("[!] %d keys have no alias.\n", len(unowned))
+	}
+	return scoped, true
+}
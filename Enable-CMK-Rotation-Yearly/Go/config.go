@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// defaults used when the matching environment variable is not set
+const (
+	defaultConcurrency       = 10
+	defaultRotationPeriod    = 365
+	defaultRotationPeriodTag = "security:rotation-period-days"
+	defaultExemptTag         = "security:rotation-exempt"
+	defaultMaxMaterialAge    = 365
+	defaultOnDemandQuota     = 25
+	defaultGrantMaxAge       = 365
+	defaultProtectTag        = "security:protect"
+	defaultRemediatedByTag   = "security:remediated-by"
+	defaultRemediatedAtTag   = "security:remediated-at"
+	defaultControlIdTag      = "security:control-id"
+	defaultRemediatedBy      = "enable-cmk-rotation"
+	defaultControlId         = "KMS.4"
+)
+
+// rotation period range accepted by KMS
+const (
+	minRotationPeriod = 90
+	maxRotationPeriod = 2560
+)
+
+// settings for a single run of the Lambda
+type Config struct {
+	Mode               string
+	Concurrency        int
+	RotationPeriodDays int32
+	RotationPeriodTag  string
+	ExemptTag          string
+	OnDemandRotation   bool
+	MaxMaterialAgeDays int
+	OnDemandQuota      int
+	PolicyAudit        bool
+	TrustedAccounts    map[string]bool
+	GrantAudit         bool
+	GrantMaxAgeDays    int
+	GrantRevokeOn      map[string]bool
+	OrgAccounts        map[string]bool
+	IncludeAliases     []string
+	ExcludeAliases     []string
+	ProtectTag         string
+	DeletionGuard      bool
+	ProvenanceTags     bool
+	RemediatedByTag    string
+	RemediatedAtTag    string
+	ControlIdTag       string
+	RemediatedBy       string
+	ControlId          string
+}
+
+func loadConfig() (Config, error) {
+	/*
+	Function that reads the run settings from the Lambda environment variables.
+
+	:return: A Config struct with defaults applied, or an error for an invalid value
+	*/
+
+	cfg := Config{
+		Mode:               modeRemediate,
+		Concurrency:        defaultConcurrency,
+		RotationPeriodDays: defaultRotationPeriod,
+		RotationPeriodTag:  defaultRotationPeriodTag,
+		ExemptTag:          defaultExemptTag,
+		MaxMaterialAgeDays: defaultMaxMaterialAge,
+		OnDemandQuota:      defaultOnDemandQuota,
+		TrustedAccounts:    map[string]bool{},
+		GrantMaxAgeDays:    defaultGrantMaxAge,
+		GrantRevokeOn:      map[string]bool{},
+		ProtectTag:         defaultProtectTag,
+		ProvenanceTags:     true,
+		RemediatedByTag:    defaultRemediatedByTag,
+		RemediatedAtTag:    defaultRemediatedAtTag,
+		ControlIdTag:       defaultControlIdTag,
+		RemediatedBy:       defaultRemediatedBy,
+		ControlId:          defaultControlId,
+	}
+
+	if val := os.Getenv("MODE"); val != "" {
+		cfg.Mode = val
+	}
+
+	if val := os.Getenv("WORKER_CONCURRENCY"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("WORKER_CONCURRENCY must be a positive integer, got %q", val)
+		}
+		cfg.Concurrency = n
+	}
+
+	if val := os.Getenv("ROTATION_PERIOD_DAYS"); val != "" {
+		days, err := parseRotationPeriod(val)
+		if err != nil {
+			return cfg, fmt.Errorf("ROTATION_PERIOD_DAYS: %v", err)
+		}
+		cfg.RotationPeriodDays = days
+	}
+
+	if val := os.Getenv("ROTATION_PERIOD_TAG"); val != "" {
+		cfg.RotationPeriodTag = val
+	}
+
+	if val := os.Getenv("EXEMPT_TAG"); val != "" {
+		cfg.ExemptTag = val
+	}
+
+	if val := os.Getenv("ON_DEMAND_ROTATION"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("ON_DEMAND_ROTATION must be true or false, got %q", val)
+		}
+		cfg.OnDemandRotation = enabled
+	}
+
+	if val := os.Getenv("MAX_KEY_MATERIAL_AGE_DAYS"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("MAX_KEY_MATERIAL_AGE_DAYS must be a positive integer, got %q", val)
+		}
+		cfg.MaxMaterialAgeDays = n
+	}
+
+	if val := os.Getenv("ON_DEMAND_ROTATION_QUOTA"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("ON_DEMAND_ROTATION_QUOTA must be zero or a positive integer, got %q", val)
+		}
+		cfg.OnDemandQuota = n
+	}
+
+	if val := os.Getenv("POLICY_AUDIT"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("POLICY_AUDIT must be true or false, got %q", val)
+		}
+		cfg.PolicyAudit = enabled
+	}
+
+	for _, account := range splitList(os.Getenv("TRUSTED_ACCOUNTS")) {
+		cfg.TrustedAccounts[account] = true
+	}
+
+	if val := os.Getenv("GRANT_AUDIT"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("GRANT_AUDIT must be true or false, got %q", val)
+		}
+		cfg.GrantAudit = enabled
+	}
+
+	if val := os.Getenv("GRANT_MAX_AGE_DAYS"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("GRANT_MAX_AGE_DAYS must be zero or a positive integer, got %q", val)
+		}
+		cfg.GrantMaxAgeDays = n
+	}
+
+	for _, flag := range splitList(os.Getenv("GRANT_REVOKE_ON")) {
+		if flag != grantOutsideOrg && flag != grantStale {
+			return cfg, fmt.Errorf("GRANT_REVOKE_ON values must be %q or %q, got %q", grantOutsideOrg, grantStale, flag)
+		}
+		cfg.GrantRevokeOn[flag] = true
+	}
+
+	cfg.IncludeAliases = splitList(os.Getenv("INCLUDE_ALIASES"))
+	cfg.ExcludeAliases = splitList(os.Getenv("EXCLUDE_ALIASES"))
+
+	for _, pattern := range append(cfg.IncludeAliases, cfg.ExcludeAliases...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return cfg, fmt.Errorf("alias pattern %q is not a valid glob", pattern)
+		}
+	}
+
+	if val := os.Getenv("PROTECT_TAG"); val != "" {
+		cfg.ProtectTag = val
+	}
+
+	if val := os.Getenv("DELETION_GUARD"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("DELETION_GUARD must be true or false, got %q", val)
+		}
+		cfg.DeletionGuard = enabled
+	}
+
+	if val := os.Getenv("PROVENANCE_TAGS"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("PROVENANCE_TAGS must be true or false, got %q", val)
+		}
+		cfg.ProvenanceTags = enabled
+	}
+
+	if val := os.Getenv("REMEDIATED_BY_TAG"); val != "" {
+		cfg.RemediatedByTag = val
+	}
+
+	if val := os.Getenv("REMEDIATED_AT_TAG"); val != "" {
+		cfg.RemediatedAtTag = val
+	}
+
+	if val := os.Getenv("CONTROL_ID_TAG"); val != "" {
+		cfg.ControlIdTag = val
+	}
+
+	// the function name set by Lambda identifies the automation unless it is overridden
+	if val := os.Getenv("AWS_LAMBDA_FUNCTION_NAME"); val != "" {
+		cfg.RemediatedBy = val
+	}
+
+	if val := os.Getenv("REMEDIATED_BY"); val != "" {
+		cfg.RemediatedBy = val
+	}
+
+	if val := os.Getenv("CONTROL_ID"); val != "" {
+		cfg.ControlId = val
+	}
+
+	return cfg, nil
+}
+
+func splitList(val string) []string {
+	/*
+	Function that splits a comma separated environment variable into its values.
+
+	:param val: The comma separated string
+	:return: A slice of the trimmed, non-empty values
+	*/
+
+	var items []string
+	for _, el := range strings.Split(val, ",") {
+		if el = strings.TrimSpace(el); el != "" {
+			items = append(items, el)
+		}
+	}
+	return items
+}
+
+func parseRotationPeriod(val string) (int32, error) {
+	/*
+	Function that parses a rotation period and checks it is in the range KMS accepts.
+
+	:param val: The rotation period in days as a string
+	:return: The rotation period in days, or an error when it is not a number from 90 to 2560
+	*/
+
+	days, err := strconv.Atoi(val)
+	if err != nil || days < minRotationPeriod || days > maxRotationPeriod {
+		return 0, fmt.Errorf("rotation period must be %d to %d days, got %q", minRotationPeriod, maxRotationPeriod, val)
+	}
+	return int32(days), nil
+}
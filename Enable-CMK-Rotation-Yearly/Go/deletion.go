@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// actions taken on a key that is pending deletion
+const (
+	deletionNone   = "none"
+	deletionCancel = "cancel-deletion"
+)
+
+func isProtected(tags map[string]string, tagKey string) bool {
+	/*
+	Function that checks whether a CMK is tagged as critical.
+
+	:param tags: The tags on the key
+	:param tagKey: The tag key that marks a key as protected, such as 'security:protect'
+	:return: A bool that is true when the tag is set to a true value
+	*/
+
+	val, ok := tags[tagKey]
+	if !ok {
+		return false
+	}
+
+	protected, _ := strconv.ParseBool(val)
+	return protected
+}
+
+func cancelDeletion(client KMSActionsAPI, keyId string) (string, error) {
+	/*
+	Function that cancels the scheduled deletion of a CMK and re-enables it.
+
+	KMS leaves a key 'Disabled' when its deletion is cancelled, so it is
+	enabled again to restore its use.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param keyId: The ID of the CMK
+	:return: The stage that failed, or an error from AWS
+	*/
+
+	err := withRetry(func() error {
+		_, err := client.CancelKeyDeletion(context.TODO(), &kms.CancelKeyDeletionInput{KeyId: aws.String(keyId)})
+		return err
+	})
+
+	if err != nil {
+		return stageCancelDeletion, err
+	}
+
+	err = withRetry(func() error {
+		_, err := client.EnableKey(context.TODO(), &kms.EnableKeyInput{KeyId: aws.String(keyId)})
+		return err
+	})
+	return stageEnableKey, err
+}
+
+func checkPendingDeletion(client KMSActionsAPI, pending []kms.DescribeKeyOutput, cfg Config) ([]PendingDeletionKey, int, []KeyFailure) {
+	/*
+	Function that reports the CMK's pending deletion and cancels the deletion of protected keys.
+
+	The deletion is only cancelled in remediate mode with cfg.DeletionGuard set,
+	in audit mode the action is what would have been taken.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param pending: A slice of key data for the customer managed keys in 'PendingDeletion'
+	:param cfg: The run settings, used for the mode, protect tag and guard
+	:return: An entry for every key pending deletion, the number of deletions cancelled, and the keys that failed with the reason
+	*/
+
+	keys := make([]PendingDeletionKey, len(pending))
+	cancelled := make([]bool, len(pending))
+	errs := make([][]KeyFailure, len(pending))
+
+	runWorkers(len(pending), cfg.Concurrency, func(i int) {
+		meta := pending[i].KeyMetadata
+		keys[i] = PendingDeletionKey{KeyId: *meta.KeyId, Arn: aws.ToString(meta.Arn), Action: deletionNone}
+
+		if meta.DeletionDate != nil {
+			keys[i].DeletionDate = meta.DeletionDate.UTC().Format(time.RFC3339)
+		}
+
+		tags, err := getKeyTags(client, *meta.KeyId)
+		if err != nil {
+			log.Println(err)
+			errs[i] = append(errs[i], newKeyFailure(*meta.KeyId, stageListTags, err))
+			return
+		}
+
+		keys[i].Protected = isProtected(tags, cfg.ProtectTag)
+		if !keys[i].Protected || !cfg.DeletionGuard {
+			return
+		}
+
+		keys[i].Action = deletionCancel
+		if cfg.Mode != modeRemediate {
+			return
+		}
+
+		if stage, err := cancelDeletion(client, *meta.KeyId); err != nil {
+			log.Println(err)
+			errs[i] = append(errs[i], newKeyFailure(*meta.KeyId, stage, err))
+			return
+		}
+
+		log.Printf("Key: %v deletion cancelled and key re-enabled.\n", *meta.KeyId)
+		cancelled[i] = true
+	})
+
+	count := 0
+	failures := []KeyFailure{}
+
+	for i := range pending {
+		if cancelled[i] {
+			count++
+		}
+		failures = append(failures, errs[i]...)
+	}
+	return keys, count, failures
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// eligibility classes for automatic rotation, only rotatable keys are remediated
+const (
+	eligibilityRotatable      = "rotatable"
+	eligibilityAsymmetric     = "ineligible-asymmetric"
+	eligibilityHMAC           = "ineligible-hmac"
+	eligibilityImported       = "ineligible-imported"
+	eligibilityCustomKeyStore = "ineligible-custom-key-store"
+	eligibilityDisabled       = "disabled"
+	eligibilityKeyState       = "ineligible-key-state"
+)
+
+func classifyKey(meta *types.KeyMetadata) string {
+	/*
+	Function that works out whether KMS can automatically rotate a CMK.
+
+	Automatic rotation is only supported on enabled symmetric encryption keys
+	with key material generated by KMS in the default key store.
+
+	:param meta: The key metadata returned by 'DescribeKey'
+	:return: The eligibility class of the CMK
+	*/
+
+	spec := string(meta.KeySpec)
+	if spec == "" {
+		spec = string(meta.CustomerMasterKeySpec)
+	}
+
+	switch {
+	case meta.Origin == types.OriginTypeAwsCloudhsm || meta.Origin == types.OriginTypeExternalKeyStore || aws.ToString(meta.CustomKeyStoreId) != "":
+		return eligibilityCustomKeyStore
+	case meta.Origin == types.OriginTypeExternal:
+		return eligibilityImported
+	case strings.HasPrefix(spec, "HMAC_") || meta.KeyUsage == types.KeyUsageTypeGenerateVerifyMac:
+		return eligibilityHMAC
+	case spec != "" && spec != string(types.KeySpecSymmetricDefault):
+		return eligibilityAsymmetric
+	case meta.KeyState == types.KeyStateDisabled:
+		return eligibilityDisabled
+	case meta.KeyState != "" && meta.KeyState != types.KeyStateEnabled:
+		return eligibilityKeyState
+	}
+	return eligibilityRotatable
+}
+
+func classifyKeys(custKeys []kms.DescribeKeyOutput) ([]kms.DescribeKeyOutput, []ReplicaKey, []IneligibleKey) {
+	/*
+	Function that splits the CMK's into the rotatable keys, the multi-Region replicas and the ineligible keys.
+
+	Replicas inherit rotation from their primary key, so they are never rotated themselves.
+
+	:param custKeys: A slice of key data for KMS keys in account/region that are customer managed.
+	:return: A slice of key data for the rotatable CMK's, and the report entries for the replica and ineligible CMK's
+	*/
+
+	var rotatable []kms.DescribeKeyOutput
+	replicas := []ReplicaKey{}
+	ineligible := []IneligibleKey{}
+
+	for _, el := range custKeys {
+		if isReplica(el.KeyMetadata) {
+			replicas = append(replicas, replicaReport(el.KeyMetadata))
+			continue
+		}
+
+		reason := classifyKey(el.KeyMetadata)
+
+		if reason == eligibilityRotatable {
+			rotatable = append(rotatable, el)
+			continue
+		}
+
+		ineligible = append(ineligible, IneligibleKey{
+			KeyId:  *el.KeyMetadata.KeyId,
+			Arn:    aws.ToString(el.KeyMetadata.Arn),
+			Reason: reason,
+		})
+	}
+	return rotatable, replicas, ineligible
+}
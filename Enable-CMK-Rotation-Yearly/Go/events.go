@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// EventBridge source and detail type of CloudTrail API call events
+const (
+	sourceKMS         = "aws.kms"
+	detailTypeAPICall = "AWS API Call via CloudTrail"
+)
+
+// CloudTrail events that trigger remediation of the single key in the event
+var keyEvents = map[string]bool{
+	"CreateKey":           true,
+	"DisableKeyRotation":  true,
+	"ImportKeyMaterial":   true,
+	"ScheduleKeyDeletion": true,
+}
+
+// the parts of a CloudTrail KMS event needed to find the key it acted on
+type cloudTrailDetail struct {
+	EventName         string `json:"eventName"`
+	ErrorCode         string `json:"errorCode"`
+	RequestParameters struct {
+		KeyId string `json:"keyId"`
+	} `json:"requestParameters"`
+	ResponseElements struct {
+		KeyMetadata struct {
+			KeyId string `json:"keyId"`
+		} `json:"keyMetadata"`
+	} `json:"responseElements"`
+}
+
+func eventKeyId(event RotationEvent) (string, bool, error) {
+	/*
+	Function that finds the key a CloudTrail event from EventBridge acted on.
+
+	Scheduled events and manual invocations are not key events and keep the full
+	sweep. 'CreateKey' carries the new key in the response, the other events carry
+	it in the request. A failed API call, or an event this Lambda does not handle,
+	is a key event with no key to check, so an EventBridge rule wider than the
+	handled events does not fail the invocation and get retried.
+
+	:param event: The invocation payload
+	:return: The ID of the key in the event, whether the payload is a key event, or an error for an unreadable event
+	*/
+
+	if event.Source != sourceKMS || event.DetailType != detailTypeAPICall {
+		return "", false, nil
+	}
+
+	var detail cloudTrailDetail
+	if err := json.Unmarshal(event.Detail, &detail); err != nil {
+		return "", true, fmt.Errorf("unable to read CloudTrail event detail, %v", err)
+	}
+
+	if !keyEvents[detail.EventName] {
+		log.Printf("[!] CloudTrail event %q is not handled, no action taken.\n", detail.EventName)
+		return "", true, nil
+	}
+
+	if detail.ErrorCode != "" {
+		return "", true, nil
+	}
+
+	if detail.EventName == "CreateKey" {
+		return detail.ResponseElements.KeyMetadata.KeyId, true, nil
+	}
+	return detail.RequestParameters.KeyId, true, nil
+}
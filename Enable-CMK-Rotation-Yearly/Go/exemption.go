@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// date layouts accepted as the expiry in the exemption tag value
+var exemptionLayouts = []string{"2006-01-02", time.RFC3339}
+
+// exemption found in the tags of a single CMK
+type exemption struct {
+	Exempt  bool
+	Expired bool
+	Until   string
+}
+
+func checkExemption(tags map[string]string, tagKey string, now time.Time) exemption {
+	/*
+	Function that checks whether a CMK is exempt from rotation through its tags.
+
+	A tag value of 'true' (or empty) exempts the key with no expiry, a date
+	(YYYY-MM-DD or RFC3339) exempts it until that date. An expired exemption no
+	longer exempts the key, so it is evaluated like any other CMK.
+
+	:param tags: The tags on the CMK
+	:param tagKey: The key of the exemption tag
+	:param now: The time the expiry is compared against
+	:return: The exemption state of the CMK
+	*/
+
+	val, ok := tags[tagKey]
+	if !ok {
+		return exemption{}
+	}
+
+	val = strings.TrimSpace(val)
+	if val == "" || strings.EqualFold(val, "true") {
+		return exemption{Exempt: true}
+	}
+
+	for _, layout := range exemptionLayouts {
+		until, err := time.Parse(layout, val)
+		if err != nil {
+			continue
+		}
+
+		// a date only expiry covers the whole of that day
+		if layout == "2006-01-02" {
+			until = until.AddDate(0, 0, 1)
+		}
+
+		if now.Before(until) {
+			return exemption{Exempt: true, Until: val}
+		}
+		return exemption{Expired: true, Until: val}
+	}
+
+	log.Printf("[!] Ignoring tag %v with value %q, expected 'true' or an expiry date\n", tagKey, val)
+	return exemption{}
+}
+
+func (e exemption) String() string {
+	/*
+	Method that describes the exemption for the report.
+
+	:return: A string such as 'exempt (until 2025-06-30)', or empty when there is no exemption tag
+	*/
+
+	switch {
+	case e.Exempt && e.Until != "":
+		return fmt.Sprintf("exempt (until %v)", e.Until)
+	case e.Exempt:
+		return "exempt"
+	case e.Expired:
+		return fmt.Sprintf("exemption expired (%v)", e.Until)
+	}
+	return ""
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/smithy-go"
+)
+
+// flags reported on a grant, also the values accepted in GRANT_REVOKE_ON
+const (
+	grantOutsideOrg = "outside-organization"
+	grantStale      = "stale"
+)
+
+// how a grant was removed
+const (
+	grantMethodRevoke = "revoke"
+	grantMethodRetire = "retire"
+)
+
+func getGrants(client KMSActionsAPI, keyId string) ([]types.GrantListEntry, error) {
+	/*
+	Function that lists every grant on a CMK.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param keyId: The ID of the CMK
+	:return: A slice of the grants on the key, or an error from AWS
+	*/
+
+	var grants []types.GrantListEntry
+	paginator := kms.NewListGrantsPaginator(client, &kms.ListGrantsInput{KeyId: aws.String(keyId)})
+
+	for paginator.HasMorePages() {
+		var resp *kms.ListGrantsOutput
+		err := withRetry(func() (err error) {
+			resp, err = paginator.NextPage(context.TODO())
+			return err
+		})
+
+		if err != nil {
+			return grants, err
+		}
+
+		grants = append(grants, resp.Grants...)
+	}
+	return grants, nil
+}
+
+func grantReport(meta *types.KeyMetadata, grant types.GrantListEntry, now time.Time, cfg Config) GrantReport {
+	/*
+	Function that builds the inventory entry for a grant and flags it.
+
+	A grant is flagged 'outside-organization' when its grantee is in an account
+	that is neither the key's own account nor in cfg.OrgAccounts, and 'stale' when
+	it is older than cfg.GrantMaxAgeDays. No grant is flagged as outside when the
+	accounts of the organization are not known. Grants to AWS service principals
+	have no account and are never flagged as outside.
+
+	:param meta: The key metadata returned by 'DescribeKey'
+	:param grant: The grant returned by 'ListGrants'
+	:param now: The time the grant age is measured at
+	:param cfg: The run settings, used for the organization accounts and grant age
+	:return: The inventory entry for the grant
+	*/
+
+	entry := GrantReport{
+		KeyId:            *meta.KeyId,
+		GrantId:          aws.ToString(grant.GrantId),
+		Name:             aws.ToString(grant.Name),
+		GranteePrincipal: aws.ToString(grant.GranteePrincipal),
+		Flags:            []string{},
+	}
+
+	if entry.GranteePrincipal == "" {
+		entry.GranteePrincipal = aws.ToString(grant.GranteeServicePrincipal)
+	}
+
+	for _, op := range grant.Operations {
+		entry.Operations = append(entry.Operations, string(op))
+	}
+
+	if grant.CreationDate != nil {
+		entry.CreationDate = grant.CreationDate.UTC().Format(time.RFC3339)
+		entry.AgeDays = int(now.Sub(*grant.CreationDate).Hours() / 24)
+	}
+
+	account := principalAccount(aws.ToString(grant.GranteePrincipal))
+	if account != "" && cfg.OrgAccounts != nil && account != aws.ToString(meta.AWSAccountId) && !cfg.OrgAccounts[account] {
+		entry.Flags = append(entry.Flags, grantOutsideOrg)
+	}
+
+	if cfg.GrantMaxAgeDays > 0 && entry.AgeDays > cfg.GrantMaxAgeDays {
+		entry.Flags = append(entry.Flags, grantStale)
+	}
+	return entry
+}
+
+func shouldRevoke(entry GrantReport, cfg Config) bool {
+	/*
+	Function that checks whether a grant matches the revocation rule.
+
+	:param entry: The inventory entry for the grant
+	:param cfg: The run settings, used for the flags in cfg.GrantRevokeOn
+	:return: A bool that is true when the grant has any of the configured flags
+	*/
+
+	for _, flag := range entry.Flags {
+		if cfg.GrantRevokeOn[flag] {
+			return true
+		}
+	}
+	return false
+}
+
+func removeGrant(client KMSActionsAPI, meta *types.KeyMetadata, grantId string) (string, error) {
+	/*
+	Function that removes a grant, revoking it as a key administrator.
+
+	When the role is not allowed to revoke, the grant is retired instead, which
+	works when the role is the retiring principal of the grant.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param meta: The key metadata returned by 'DescribeKey'
+	:param grantId: The ID of the grant
+	:return: The method the grant was removed with, or an error from AWS
+	*/
+
+	err := withRetry(func() error {
+		_, err := client.RevokeGrant(context.TODO(), &kms.RevokeGrantInput{KeyId: meta.KeyId, GrantId: aws.String(grantId)})
+		return err
+	})
+
+	var apiErr smithy.APIError
+	if err == nil || !errors.As(err, &apiErr) || apiErr.ErrorCode() != "AccessDeniedException" {
+		return grantMethodRevoke, err
+	}
+
+	err = withRetry(func() error {
+		_, err := client.RetireGrant(context.TODO(), &kms.RetireGrantInput{KeyId: meta.Arn, GrantId: aws.String(grantId)})
+		return err
+	})
+	return grantMethodRetire, err
+}
+
+func auditGrants(client KMSActionsAPI, custKeys []kms.DescribeKeyOutput, cfg Config) ([]GrantReport, []RevokedGrant, []KeyFailure) {
+	/*
+	Function that builds the grant inventory of the CMK's and removes grants matching the revocation rule.
+
+	Grants are only removed in remediate mode, when cfg.GrantRevokeOn is set. The
+	grant calls are spread over a pool of cfg.Concurrency workers, the results
+	keep the order of custKeys.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param custKeys: A slice of key data for KMS keys in account/region that are customer managed.
+	:param cfg: The run settings, used for the mode, worker concurrency and grant rules
+	:return: The grant inventory, the grants that were removed, and the keys or grants that failed with the reason
+	*/
+
+	inventory := make([][]GrantReport, len(custKeys))
+	revoked := make([][]RevokedGrant, len(custKeys))
+	errs := make([][]KeyFailure, len(custKeys))
+	revoke := len(cfg.GrantRevokeOn) > 0 && cfg.Mode == modeRemediate
+
+	runWorkers(len(custKeys), cfg.Concurrency, func(i int) {
+		meta := custKeys[i].KeyMetadata
+		grants, err := getGrants(client, *meta.KeyId)
+
+		if err != nil {
+			log.Println(err)
+			errs[i] = append(errs[i], newKeyFailure(*meta.KeyId, stageListGrants, err))
+			return
+		}
+
+		for _, grant := range grants {
+			entry := grantReport(meta, grant, time.Now(), cfg)
+			inventory[i] = append(inventory[i], entry)
+
+			if !revoke || !shouldRevoke(entry, cfg) {
+				continue
+			}
+
+			method, err := removeGrant(client, meta, entry.GrantId)
+			if err != nil {
+				log.Println(err)
+				errs[i] = append(errs[i], newKeyFailure(*meta.KeyId, stageRevokeGrant, err))
+				continue
+			}
+
+			log.Printf("Grant: %v on key %v to %v removed (%v).\n", entry.GrantId, *meta.KeyId, entry.GranteePrincipal, method)
+			revoked[i] = append(revoked[i], RevokedGrant{
+				KeyId:            entry.KeyId,
+				GrantId:          entry.GrantId,
+				GranteePrincipal: entry.GranteePrincipal,
+				Flags:            entry.Flags,
+				Method:           method,
+			})
+		}
+	})
+
+	grants := []GrantReport{}
+	removed := []RevokedGrant{}
+	failures := []KeyFailure{}
+
+	for i := range custKeys {
+		grants = append(grants, inventory[i]...)
+		removed = append(removed, revoked[i]...)
+		failures = append(failures, errs[i]...)
+	}
+	return grants, removed, failures
+}
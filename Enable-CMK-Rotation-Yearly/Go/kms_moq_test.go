@@ -19,18 +19,54 @@ var _ KMSActionsAPI = &KMSActionsAPIMock{}
 //
 //		// make and configure a mocked KMSActionsAPI
 //		mockedKMSActionsAPI := &KMSActionsAPIMock{
+//			CancelKeyDeletionFunc: func(ctx context.Context, params *kms.CancelKeyDeletionInput, optFns ...func(*kms.Options)) (*kms.CancelKeyDeletionOutput, error) {
+//				panic("mock out the CancelKeyDeletion method")
+//			},
 //			DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
 //				panic("mock out the DescribeKey method")
 //			},
+//			EnableKeyFunc: func(ctx context.Context, params *kms.EnableKeyInput, optFns ...func(*kms.Options)) (*kms.EnableKeyOutput, error) {
+//				panic("mock out the EnableKey method")
+//			},
 //			EnableKeyRotationFunc: func(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
 //				panic("mock out the EnableKeyRotation method")
 //			},
+//			GetKeyPolicyFunc: func(ctx context.Context, params *kms.GetKeyPolicyInput, optFns ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error) {
+//				panic("mock out the GetKeyPolicy method")
+//			},
 //			GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
 //				panic("mock out the GetKeyRotationStatus method")
 //			},
+//			ListAliasesFunc: func(ctx context.Context, params *kms.ListAliasesInput, optFns ...func(*kms.Options)) (*kms.ListAliasesOutput, error) {
+//				panic("mock out the ListAliases method")
+//			},
+//			ListGrantsFunc: func(ctx context.Context, params *kms.ListGrantsInput, optFns ...func(*kms.Options)) (*kms.ListGrantsOutput, error) {
+//				panic("mock out the ListGrants method")
+//			},
+//			ListKeyPoliciesFunc: func(ctx context.Context, params *kms.ListKeyPoliciesInput, optFns ...func(*kms.Options)) (*kms.ListKeyPoliciesOutput, error) {
+//				panic("mock out the ListKeyPolicies method")
+//			},
+//			ListKeyRotationsFunc: func(ctx context.Context, params *kms.ListKeyRotationsInput, optFns ...func(*kms.Options)) (*kms.ListKeyRotationsOutput, error) {
+//				panic("mock out the ListKeyRotations method")
+//			},
 //			ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
 //				panic("mock out the ListKeys method")
 //			},
+//			ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+//				panic("mock out the ListResourceTags method")
+//			},
+//			RetireGrantFunc: func(ctx context.Context, params *kms.RetireGrantInput, optFns ...func(*kms.Options)) (*kms.RetireGrantOutput, error) {
+//				panic("mock out the RetireGrant method")
+//			},
+//			RevokeGrantFunc: func(ctx context.Context, params *kms.RevokeGrantInput, optFns ...func(*kms.Options)) (*kms.RevokeGrantOutput, error) {
+//				panic("mock out the RevokeGrant method")
+//			},
+//			RotateKeyOnDemandFunc: func(ctx context.Context, params *kms.RotateKeyOnDemandInput, optFns ...func(*kms.Options)) (*kms.RotateKeyOnDemandOutput, error) {
+//				panic("mock out the RotateKeyOnDemand method")
+//			},
+//			TagResourceFunc: func(ctx context.Context, params *kms.TagResourceInput, optFns ...func(*kms.Options)) (*kms.TagResourceOutput, error) {
+//				panic("mock out the TagResource method")
+//			},
 //		}
 //
 //		// use mockedKMSActionsAPI in code that requires KMSActionsAPI
@@ -38,20 +74,65 @@ var _ KMSActionsAPI = &KMSActionsAPIMock{}
 //
 //	}
 type KMSActionsAPIMock struct {
+	// CancelKeyDeletionFunc mocks the CancelKeyDeletion method.
+	CancelKeyDeletionFunc func(ctx context.Context, params *kms.CancelKeyDeletionInput, optFns ...func(*kms.Options)) (*kms.CancelKeyDeletionOutput, error)
+
 	// DescribeKeyFunc mocks the DescribeKey method.
 	DescribeKeyFunc func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error)
 
+	// EnableKeyFunc mocks the EnableKey method.
+	EnableKeyFunc func(ctx context.Context, params *kms.EnableKeyInput, optFns ...func(*kms.Options)) (*kms.EnableKeyOutput, error)
+
 	// EnableKeyRotationFunc mocks the EnableKeyRotation method.
 	EnableKeyRotationFunc func(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error)
 
+	// GetKeyPolicyFunc mocks the GetKeyPolicy method.
+	GetKeyPolicyFunc func(ctx context.Context, params *kms.GetKeyPolicyInput, optFns ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error)
+
 	// GetKeyRotationStatusFunc mocks the GetKeyRotationStatus method.
 	GetKeyRotationStatusFunc func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error)
 
+	// ListAliasesFunc mocks the ListAliases method.
+	ListAliasesFunc func(ctx context.Context, params *kms.ListAliasesInput, optFns ...func(*kms.Options)) (*kms.ListAliasesOutput, error)
+
+	// ListGrantsFunc mocks the ListGrants method.
+	ListGrantsFunc func(ctx context.Context, params *kms.ListGrantsInput, optFns ...func(*kms.Options)) (*kms.ListGrantsOutput, error)
+
+	// ListKeyPoliciesFunc mocks the ListKeyPolicies method.
+	ListKeyPoliciesFunc func(ctx context.Context, params *kms.ListKeyPoliciesInput, optFns ...func(*kms.Options)) (*kms.ListKeyPoliciesOutput, error)
+
+	// ListKeyRotationsFunc mocks the ListKeyRotations method.
+	ListKeyRotationsFunc func(ctx context.Context, params *kms.ListKeyRotationsInput, optFns ...func(*kms.Options)) (*kms.ListKeyRotationsOutput, error)
+
 	// ListKeysFunc mocks the ListKeys method.
 	ListKeysFunc func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error)
 
+	// ListResourceTagsFunc mocks the ListResourceTags method.
+	ListResourceTagsFunc func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error)
+
+	// RetireGrantFunc mocks the RetireGrant method.
+	RetireGrantFunc func(ctx context.Context, params *kms.RetireGrantInput, optFns ...func(*kms.Options)) (*kms.RetireGrantOutput, error)
+
+	// RevokeGrantFunc mocks the RevokeGrant method.
+	RevokeGrantFunc func(ctx context.Context, params *kms.RevokeGrantInput, optFns ...func(*kms.Options)) (*kms.RevokeGrantOutput, error)
+
+	// RotateKeyOnDemandFunc mocks the RotateKeyOnDemand method.
+	RotateKeyOnDemandFunc func(ctx context.Context, params *kms.RotateKeyOnDemandInput, optFns ...func(*kms.Options)) (*kms.RotateKeyOnDemandOutput, error)
+
+	// TagResourceFunc mocks the TagResource method.
+	TagResourceFunc func(ctx context.Context, params *kms.TagResourceInput, optFns ...func(*kms.Options)) (*kms.TagResourceOutput, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// CancelKeyDeletion holds details about calls to the CancelKeyDeletion method.
+		CancelKeyDeletion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.CancelKeyDeletionInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
 		// DescribeKey holds details about calls to the DescribeKey method.
 		DescribeKey []struct {
 			// Ctx is the ctx argument value.
@@ -61,6 +142,15 @@ type KMSActionsAPIMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*kms.Options)
 		}
+		// EnableKey holds details about calls to the EnableKey method.
+		EnableKey []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.EnableKeyInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
 		// EnableKeyRotation holds details about calls to the EnableKeyRotation method.
 		EnableKeyRotation []struct {
 			// Ctx is the ctx argument value.
@@ -70,6 +160,15 @@ type KMSActionsAPIMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*kms.Options)
 		}
+		// GetKeyPolicy holds details about calls to the GetKeyPolicy method.
+		GetKeyPolicy []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.GetKeyPolicyInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
 		// GetKeyRotationStatus holds details about calls to the GetKeyRotationStatus method.
 		GetKeyRotationStatus []struct {
 			// Ctx is the ctx argument value.
@@ -79,6 +178,42 @@ type KMSActionsAPIMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*kms.Options)
 		}
+		// ListAliases holds details about calls to the ListAliases method.
+		ListAliases []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.ListAliasesInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
+		// ListGrants holds details about calls to the ListGrants method.
+		ListGrants []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.ListGrantsInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
+		// ListKeyPolicies holds details about calls to the ListKeyPolicies method.
+		ListKeyPolicies []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.ListKeyPoliciesInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
+		// ListKeyRotations holds details about calls to the ListKeyRotations method.
+		ListKeyRotations []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.ListKeyRotationsInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
 		// ListKeys holds details about calls to the ListKeys method.
 		ListKeys []struct {
 			// Ctx is the ctx argument value.
@@ -88,11 +223,108 @@ type KMSActionsAPIMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*kms.Options)
 		}
+		// ListResourceTags holds details about calls to the ListResourceTags method.
+		ListResourceTags []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.ListResourceTagsInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
+		// RetireGrant holds details about calls to the RetireGrant method.
+		RetireGrant []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.RetireGrantInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
+		// RevokeGrant holds details about calls to the RevokeGrant method.
+		RevokeGrant []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.RevokeGrantInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
+		// RotateKeyOnDemand holds details about calls to the RotateKeyOnDemand method.
+		RotateKeyOnDemand []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.RotateKeyOnDemandInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
+		// TagResource holds details about calls to the TagResource method.
+		TagResource []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *kms.TagResourceInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*kms.Options)
+		}
 	}
+	lockCancelKeyDeletion    sync.RWMutex
 	lockDescribeKey          sync.RWMutex
+	lockEnableKey            sync.RWMutex
 	lockEnableKeyRotation    sync.RWMutex
+	lockGetKeyPolicy         sync.RWMutex
 	lockGetKeyRotationStatus sync.RWMutex
+	lockListAliases          sync.RWMutex
+	lockListGrants           sync.RWMutex
+	lockListKeyPolicies      sync.RWMutex
+	lockListKeyRotations     sync.RWMutex
 	lockListKeys             sync.RWMutex
+	lockListResourceTags     sync.RWMutex
+	lockRetireGrant          sync.RWMutex
+	lockRevokeGrant          sync.RWMutex
+	lockRotateKeyOnDemand    sync.RWMutex
+	lockTagResource          sync.RWMutex
+}
+
+// CancelKeyDeletion calls CancelKeyDeletionFunc.
+func (mock *KMSActionsAPIMock) CancelKeyDeletion(ctx context.Context, params *kms.CancelKeyDeletionInput, optFns ...func(*kms.Options)) (*kms.CancelKeyDeletionOutput, error) {
+	if mock.CancelKeyDeletionFunc == nil {
+		panic("KMSActionsAPIMock.CancelKeyDeletionFunc: method is nil but KMSActionsAPI.CancelKeyDeletion was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.CancelKeyDeletionInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockCancelKeyDeletion.Lock()
+	mock.calls.CancelKeyDeletion = append(mock.calls.CancelKeyDeletion, callInfo)
+	mock.lockCancelKeyDeletion.Unlock()
+	return mock.CancelKeyDeletionFunc(ctx, params, optFns...)
+}
+
+// CancelKeyDeletionCalls gets all the calls that were made to CancelKeyDeletion.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.CancelKeyDeletionCalls())
+func (mock *KMSActionsAPIMock) CancelKeyDeletionCalls() []struct {
+	Ctx    context.Context
+	Params *kms.CancelKeyDeletionInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.CancelKeyDeletionInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockCancelKeyDeletion.RLock()
+	calls = mock.calls.CancelKeyDeletion
+	mock.lockCancelKeyDeletion.RUnlock()
+	return calls
 }
 
 // DescribeKey calls DescribeKeyFunc.
@@ -135,6 +367,46 @@ func (mock *KMSActionsAPIMock) DescribeKeyCalls() []struct {
 	return calls
 }
 
+// EnableKey calls EnableKeyFunc.
+func (mock *KMSActionsAPIMock) EnableKey(ctx context.Context, params *kms.EnableKeyInput, optFns ...func(*kms.Options)) (*kms.EnableKeyOutput, error) {
+	if mock.EnableKeyFunc == nil {
+		panic("KMSActionsAPIMock.EnableKeyFunc: method is nil but KMSActionsAPI.EnableKey was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.EnableKeyInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockEnableKey.Lock()
+	mock.calls.EnableKey = append(mock.calls.EnableKey, callInfo)
+	mock.lockEnableKey.Unlock()
+	return mock.EnableKeyFunc(ctx, params, optFns...)
+}
+
+// EnableKeyCalls gets all the calls that were made to EnableKey.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.EnableKeyCalls())
+func (mock *KMSActionsAPIMock) EnableKeyCalls() []struct {
+	Ctx    context.Context
+	Params *kms.EnableKeyInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.EnableKeyInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockEnableKey.RLock()
+	calls = mock.calls.EnableKey
+	mock.lockEnableKey.RUnlock()
+	return calls
+}
+
 // EnableKeyRotation calls EnableKeyRotationFunc.
 func (mock *KMSActionsAPIMock) EnableKeyRotation(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
 	if mock.EnableKeyRotationFunc == nil {
@@ -175,6 +447,46 @@ func (mock *KMSActionsAPIMock) EnableKeyRotationCalls() []struct {
 	return calls
 }
 
+// GetKeyPolicy calls GetKeyPolicyFunc.
+func (mock *KMSActionsAPIMock) GetKeyPolicy(ctx context.Context, params *kms.GetKeyPolicyInput, optFns ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error) {
+	if mock.GetKeyPolicyFunc == nil {
+		panic("KMSActionsAPIMock.GetKeyPolicyFunc: method is nil but KMSActionsAPI.GetKeyPolicy was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.GetKeyPolicyInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockGetKeyPolicy.Lock()
+	mock.calls.GetKeyPolicy = append(mock.calls.GetKeyPolicy, callInfo)
+	mock.lockGetKeyPolicy.Unlock()
+	return mock.GetKeyPolicyFunc(ctx, params, optFns...)
+}
+
+// GetKeyPolicyCalls gets all the calls that were made to GetKeyPolicy.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.GetKeyPolicyCalls())
+func (mock *KMSActionsAPIMock) GetKeyPolicyCalls() []struct {
+	Ctx    context.Context
+	Params *kms.GetKeyPolicyInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.GetKeyPolicyInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockGetKeyPolicy.RLock()
+	calls = mock.calls.GetKeyPolicy
+	mock.lockGetKeyPolicy.RUnlock()
+	return calls
+}
+
 // GetKeyRotationStatus calls GetKeyRotationStatusFunc.
 func (mock *KMSActionsAPIMock) GetKeyRotationStatus(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
 	if mock.GetKeyRotationStatusFunc == nil {
@@ -215,6 +527,166 @@ func (mock *KMSActionsAPIMock) GetKeyRotationStatusCalls() []struct {
 	return calls
 }
 
+// ListAliases calls ListAliasesFunc.
+func (mock *KMSActionsAPIMock) ListAliases(ctx context.Context, params *kms.ListAliasesInput, optFns ...func(*kms.Options)) (*kms.ListAliasesOutput, error) {
+	if mock.ListAliasesFunc == nil {
+		panic("KMSActionsAPIMock.ListAliasesFunc: method is nil but KMSActionsAPI.ListAliases was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.ListAliasesInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockListAliases.Lock()
+	mock.calls.ListAliases = append(mock.calls.ListAliases, callInfo)
+	mock.lockListAliases.Unlock()
+	return mock.ListAliasesFunc(ctx, params, optFns...)
+}
+
+// ListAliasesCalls gets all the calls that were made to ListAliases.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.ListAliasesCalls())
+func (mock *KMSActionsAPIMock) ListAliasesCalls() []struct {
+	Ctx    context.Context
+	Params *kms.ListAliasesInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.ListAliasesInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockListAliases.RLock()
+	calls = mock.calls.ListAliases
+	mock.lockListAliases.RUnlock()
+	return calls
+}
+
+// ListGrants calls ListGrantsFunc.
+func (mock *KMSActionsAPIMock) ListGrants(ctx context.Context, params *kms.ListGrantsInput, optFns ...func(*kms.Options)) (*kms.ListGrantsOutput, error) {
+	if mock.ListGrantsFunc == nil {
+		panic("KMSActionsAPIMock.ListGrantsFunc: method is nil but KMSActionsAPI.ListGrants was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.ListGrantsInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockListGrants.Lock()
+	mock.calls.ListGrants = append(mock.calls.ListGrants, callInfo)
+	mock.lockListGrants.Unlock()
+	return mock.ListGrantsFunc(ctx, params, optFns...)
+}
+
+// ListGrantsCalls gets all the calls that were made to ListGrants.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.ListGrantsCalls())
+func (mock *KMSActionsAPIMock) ListGrantsCalls() []struct {
+	Ctx    context.Context
+	Params *kms.ListGrantsInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.ListGrantsInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockListGrants.RLock()
+	calls = mock.calls.ListGrants
+	mock.lockListGrants.RUnlock()
+	return calls
+}
+
+// ListKeyPolicies calls ListKeyPoliciesFunc.
+func (mock *KMSActionsAPIMock) ListKeyPolicies(ctx context.Context, params *kms.ListKeyPoliciesInput, optFns ...func(*kms.Options)) (*kms.ListKeyPoliciesOutput, error) {
+	if mock.ListKeyPoliciesFunc == nil {
+		panic("KMSActionsAPIMock.ListKeyPoliciesFunc: method is nil but KMSActionsAPI.ListKeyPolicies was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.ListKeyPoliciesInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockListKeyPolicies.Lock()
+	mock.calls.ListKeyPolicies = append(mock.calls.ListKeyPolicies, callInfo)
+	mock.lockListKeyPolicies.Unlock()
+	return mock.ListKeyPoliciesFunc(ctx, params, optFns...)
+}
+
+// ListKeyPoliciesCalls gets all the calls that were made to ListKeyPolicies.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.ListKeyPoliciesCalls())
+func (mock *KMSActionsAPIMock) ListKeyPoliciesCalls() []struct {
+	Ctx    context.Context
+	Params *kms.ListKeyPoliciesInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.ListKeyPoliciesInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockListKeyPolicies.RLock()
+	calls = mock.calls.ListKeyPolicies
+	mock.lockListKeyPolicies.RUnlock()
+	return calls
+}
+
+// ListKeyRotations calls ListKeyRotationsFunc.
+func (mock *KMSActionsAPIMock) ListKeyRotations(ctx context.Context, params *kms.ListKeyRotationsInput, optFns ...func(*kms.Options)) (*kms.ListKeyRotationsOutput, error) {
+	if mock.ListKeyRotationsFunc == nil {
+		panic("KMSActionsAPIMock.ListKeyRotationsFunc: method is nil but KMSActionsAPI.ListKeyRotations was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.ListKeyRotationsInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockListKeyRotations.Lock()
+	mock.calls.ListKeyRotations = append(mock.calls.ListKeyRotations, callInfo)
+	mock.lockListKeyRotations.Unlock()
+	return mock.ListKeyRotationsFunc(ctx, params, optFns...)
+}
+
+// ListKeyRotationsCalls gets all the calls that were made to ListKeyRotations.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.ListKeyRotationsCalls())
+func (mock *KMSActionsAPIMock) ListKeyRotationsCalls() []struct {
+	Ctx    context.Context
+	Params *kms.ListKeyRotationsInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.ListKeyRotationsInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockListKeyRotations.RLock()
+	calls = mock.calls.ListKeyRotations
+	mock.lockListKeyRotations.RUnlock()
+	return calls
+}
+
 // ListKeys calls ListKeysFunc.
 func (mock *KMSActionsAPIMock) ListKeys(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
 	if mock.ListKeysFunc == nil {
@@ -254,3 +726,203 @@ func (mock *KMSActionsAPIMock) ListKeysCalls() []struct {
 	mock.lockListKeys.RUnlock()
 	return calls
 }
+
+// ListResourceTags calls ListResourceTagsFunc.
+func (mock *KMSActionsAPIMock) ListResourceTags(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+	if mock.ListResourceTagsFunc == nil {
+		panic("KMSActionsAPIMock.ListResourceTagsFunc: method is nil but KMSActionsAPI.ListResourceTags was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.ListResourceTagsInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockListResourceTags.Lock()
+	mock.calls.ListResourceTags = append(mock.calls.ListResourceTags, callInfo)
+	mock.lockListResourceTags.Unlock()
+	return mock.ListResourceTagsFunc(ctx, params, optFns...)
+}
+
+// ListResourceTagsCalls gets all the calls that were made to ListResourceTags.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.ListResourceTagsCalls())
+func (mock *KMSActionsAPIMock) ListResourceTagsCalls() []struct {
+	Ctx    context.Context
+	Params *kms.ListResourceTagsInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.ListResourceTagsInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockListResourceTags.RLock()
+	calls = mock.calls.ListResourceTags
+	mock.lockListResourceTags.RUnlock()
+	return calls
+}
+
+// RetireGrant calls RetireGrantFunc.
+func (mock *KMSActionsAPIMock) RetireGrant(ctx context.Context, params *kms.RetireGrantInput, optFns ...func(*kms.Options)) (*kms.RetireGrantOutput, error) {
+	if mock.RetireGrantFunc == nil {
+		panic("KMSActionsAPIMock.RetireGrantFunc: method is nil but KMSActionsAPI.RetireGrant was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.RetireGrantInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockRetireGrant.Lock()
+	mock.calls.RetireGrant = append(mock.calls.RetireGrant, callInfo)
+	mock.lockRetireGrant.Unlock()
+	return mock.RetireGrantFunc(ctx, params, optFns...)
+}
+
+// RetireGrantCalls gets all the calls that were made to RetireGrant.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.RetireGrantCalls())
+func (mock *KMSActionsAPIMock) RetireGrantCalls() []struct {
+	Ctx    context.Context
+	Params *kms.RetireGrantInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.RetireGrantInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockRetireGrant.RLock()
+	calls = mock.calls.RetireGrant
+	mock.lockRetireGrant.RUnlock()
+	return calls
+}
+
+// RevokeGrant calls RevokeGrantFunc.
+func (mock *KMSActionsAPIMock) RevokeGrant(ctx context.Context, params *kms.RevokeGrantInput, optFns ...func(*kms.Options)) (*kms.RevokeGrantOutput, error) {
+	if mock.RevokeGrantFunc == nil {
+		panic("KMSActionsAPIMock.RevokeGrantFunc: method is nil but KMSActionsAPI.RevokeGrant was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.RevokeGrantInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockRevokeGrant.Lock()
+	mock.calls.RevokeGrant = append(mock.calls.RevokeGrant, callInfo)
+	mock.lockRevokeGrant.Unlock()
+	return mock.RevokeGrantFunc(ctx, params, optFns...)
+}
+
+// RevokeGrantCalls gets all the calls that were made to RevokeGrant.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.RevokeGrantCalls())
+func (mock *KMSActionsAPIMock) RevokeGrantCalls() []struct {
+	Ctx    context.Context
+	Params *kms.RevokeGrantInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.RevokeGrantInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockRevokeGrant.RLock()
+	calls = mock.calls.RevokeGrant
+	mock.lockRevokeGrant.RUnlock()
+	return calls
+}
+
+// RotateKeyOnDemand calls RotateKeyOnDemandFunc.
+func (mock *KMSActionsAPIMock) RotateKeyOnDemand(ctx context.Context, params *kms.RotateKeyOnDemandInput, optFns ...func(*kms.Options)) (*kms.RotateKeyOnDemandOutput, error) {
+	if mock.RotateKeyOnDemandFunc == nil {
+		panic("KMSActionsAPIMock.RotateKeyOnDemandFunc: method is nil but KMSActionsAPI.RotateKeyOnDemand was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.RotateKeyOnDemandInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockRotateKeyOnDemand.Lock()
+	mock.calls.RotateKeyOnDemand = append(mock.calls.RotateKeyOnDemand, callInfo)
+	mock.lockRotateKeyOnDemand.Unlock()
+	return mock.RotateKeyOnDemandFunc(ctx, params, optFns...)
+}
+
+// RotateKeyOnDemandCalls gets all the calls that were made to RotateKeyOnDemand.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.RotateKeyOnDemandCalls())
+func (mock *KMSActionsAPIMock) RotateKeyOnDemandCalls() []struct {
+	Ctx    context.Context
+	Params *kms.RotateKeyOnDemandInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.RotateKeyOnDemandInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockRotateKeyOnDemand.RLock()
+	calls = mock.calls.RotateKeyOnDemand
+	mock.lockRotateKeyOnDemand.RUnlock()
+	return calls
+}
+
+// TagResource calls TagResourceFunc.
+func (mock *KMSActionsAPIMock) TagResource(ctx context.Context, params *kms.TagResourceInput, optFns ...func(*kms.Options)) (*kms.TagResourceOutput, error) {
+	if mock.TagResourceFunc == nil {
+		panic("KMSActionsAPIMock.TagResourceFunc: method is nil but KMSActionsAPI.TagResource was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *kms.TagResourceInput
+		OptFns []func(*kms.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockTagResource.Lock()
+	mock.calls.TagResource = append(mock.calls.TagResource, callInfo)
+	mock.lockTagResource.Unlock()
+	return mock.TagResourceFunc(ctx, params, optFns...)
+}
+
+// TagResourceCalls gets all the calls that were made to TagResource.
+// Check the length with:
+//
+//	len(mockedKMSActionsAPI.TagResourceCalls())
+func (mock *KMSActionsAPIMock) TagResourceCalls() []struct {
+	Ctx    context.Context
+	Params *kms.TagResourceInput
+	OptFns []func(*kms.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *kms.TagResourceInput
+		OptFns []func(*kms.Options)
+	}
+	mock.lockTagResource.RLock()
+	calls = mock.calls.TagResource
+	mock.lockTagResource.RUnlock()
+	return calls
+}
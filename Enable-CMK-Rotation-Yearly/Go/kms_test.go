@@ -4,14 +4,40 @@ import (
 	"testing"
 	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
+	"time"
 
 	"gotest.tools/assert"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/smithy-go"
 )
 
 var custKeysMock []kms.DescribeKeyOutput
 var keyId string = "1234abcd-12ab-34cd-56ef-1234567890ab"
+var testCfg = Config{Mode: modeRemediate, Concurrency: 4, RotationPeriodDays: 365, RotationPeriodTag: defaultRotationPeriodTag, ExemptTag: defaultExemptTag}
+
+func keyDetails() kms.DescribeKeyOutput {
+
+	// Read json file containing example key detail for a single key, for tests that must not depend on custKeysMock
+	data, _ := ioutil.ReadFile("test-data/key-details.json")
+	var key kms.DescribeKeyOutput;
+	json.Unmarshal(data, &key);
+	return key
+}
+
+func listAliasesMock(ctx context.Context, params *kms.ListAliasesInput, optFns ...func(*kms.Options)) (*kms.ListAliasesOutput, error) {
+
+	// Read json file containing the example aliases
+	data, _ := ioutil.ReadFile("test-data/list-aliases.json")
+	var aliases kms.ListAliasesOutput;
+	json.Unmarshal(data, &aliases);
+	return &aliases, nil
+}
 
 
 func TestListKeysAndGetCustKeys(t *testing.T) {
@@ -41,7 +67,7 @@ func TestListKeysAndGetCustKeys(t *testing.T) {
 		// use mockedKMSActionsAPI in code that requires KMSActionsAPI
 		// and then make assertions.
 	
-		listOfKeys := listKeys(mockedKMSActionsAPIForList)
+		listOfKeys, _ := listKeys(mockedKMSActionsAPIForList)
 
 		assert.Equal(t,keyId, *listOfKeys[0].KeyId)
 
@@ -59,7 +85,7 @@ func TestListKeysAndGetCustKeys(t *testing.T) {
 				return &kmsOutput,nil;
 			},
 		}
-		customerManagedKeys := getCustKeys(mockedKMSActionsAPIDescribe, listOfKeys)
+		customerManagedKeys, _, _, _ := getCustKeys(mockedKMSActionsAPIDescribe, listOfKeys, testCfg)
 
 		// this append process is so that the global var can be used in the following tests
 		custKeysMock = append(custKeysMock, customerManagedKeys[0])
@@ -90,11 +116,16 @@ func TestGetKeyRotationStatus(t *testing.T) {
 				json.Unmarshal(data, &kmsOutput);
 				return &kmsOutput,nil;
 			},
+			ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+				return &kms.ListResourceTagsOutput{}, nil
+			},
 		}
 
-		nonRotatedKeyList := getRotationStatus(mockedKMSActionsAPI, custKeysMock)
+		statuses, _ := getRotationStatus(mockedKMSActionsAPI, custKeysMock, testCfg)
+		nonRotatedKeyList := nonCompliant(statuses)
 
-		assert.Equal(t, keyId, *nonRotatedKeyList[0].KeyMetadata.KeyId)
+		assert.Equal(t, keyId, *nonRotatedKeyList[0].Key.KeyMetadata.KeyId)
+		assert.Equal(t, testCfg.RotationPeriodDays, nonRotatedKeyList[0].TargetDays)
 
 
 }
@@ -119,9 +150,908 @@ func TestSetKeyRotation(t *testing.T) {
 		},
 	}
 
-	result := setKeyRotation(mockedKMSActionsAPI, custKeysMock)
+	failures := setKeyRotation(mockedKMSActionsAPI, []keyStatus{{Key: custKeysMock[0], TargetDays: 365}})
+
+	assert.Equal(t, 0, len(failures))
+	assert.Equal(t, int32(365), *mockedKMSActionsAPI.EnableKeyRotationCalls()[0].Params.RotationPeriodInDays)
+
+
+}
+
+func TestAuditMode(t *testing.T) {
+	/*
+	This test function will look at the runRotation function in audit mode.
+	The mock does not implement 'EnableKeyRotation', so any attempt to
+	remediate a key will panic the test. The returned report should list
+	the non-rotated key and the action that would have been taken.
+	*/
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListAliasesFunc: listAliasesMock,
+		ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+
+			var kmsOutput kms.ListKeysOutput
+			data, _ := ioutil.ReadFile("test-data/kms-key-list.json")
+
+			json.Unmarshal(data, &kmsOutput);
+			return &kmsOutput,nil;
+		},
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+
+			var kmsOutput kms.DescribeKeyOutput
+			data, _ := ioutil.ReadFile("test-data/key-details.json")
+
+			json.Unmarshal(data, &kmsOutput);
+			return &kmsOutput,nil;
+		},
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+			return &kms.ListResourceTagsOutput{}, nil
+		},
+		GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
+
+			var kmsOutput kms.GetKeyRotationStatusOutput
+			data, _ := ioutil.ReadFile("test-data/get-rotation-status.json")
+
+			json.Unmarshal(data, &kmsOutput);
+			return &kmsOutput,nil;
+		},
+	}
+
+	report := runRotation(mockedKMSActionsAPI, nil, Config{Mode: modeAudit, Concurrency: 4, RotationPeriodDays: 365})
+
+	assert.Equal(t, modeAudit, report.Mode)
+	assert.Equal(t, 3, report.KeysScanned)
+	assert.Equal(t, 0, report.Remediated)
+	assert.Equal(t, keyId, report.Keys[0].KeyId)
+	assert.Equal(t, false, report.Keys[0].RotationEnabled)
+	assert.Equal(t, actionEnableRotation, report.Keys[0].Action)
+	assert.Equal(t, 0, len(mockedKMSActionsAPI.EnableKeyRotationCalls()))
+}
+
+
+func TestListKeysPagination(t *testing.T) {
+	/*
+	This test function will look at the listKeys function when the
+	'ListKeys' response is truncated. The mock returns the example key list
+	as the first page with a 'NextMarker', and a single key on the second page.
+	*/
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+
+			if params.Marker == nil {
+				var kmsOutput kms.ListKeysOutput
+				data, _ := ioutil.ReadFile("test-data/kms-key-list.json")
+
+				json.Unmarshal(data, &kmsOutput);
+				kmsOutput.Truncated = true
+				kmsOutput.NextMarker = aws.String("page2")
+				return &kmsOutput,nil;
+			}
+
+			return &kms.ListKeysOutput{
+				Keys: []types.KeyListEntry{{KeyId: aws.String("last-key")}},
+			}, nil
+		},
+	}
+
+	listOfKeys, err := listKeys(mockedKMSActionsAPI)
+
+	assert.NilError(t, err)
+	assert.Equal(t, 4, len(listOfKeys))
+	assert.Equal(t, "last-key", *listOfKeys[3].KeyId)
+	assert.Equal(t, "page2", *mockedKMSActionsAPI.ListKeysCalls()[1].Params.Marker)
+}
+
+func TestGetCustKeysOrder(t *testing.T) {
+	/*
+	This test function will look at the order of the getCustKeys results.
+	The mock answers the first keys slowest, so the workers finish out of
+	order, but the returned keys must still follow the input order.
+	*/
+
+	var keys []types.KeyListEntry
+	for _, id := range []string{"key-a", "key-b", "key-c", "key-d", "key-e"} {
+		keys = append(keys, types.KeyListEntry{KeyId: aws.String(id)})
+	}
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+
+			delay := time.Duration('f' - (*params.KeyId)[4]) * time.Millisecond
+			time.Sleep(delay)
+
+			return &kms.DescribeKeyOutput{
+				KeyMetadata: &types.KeyMetadata{
+					KeyId:      params.KeyId,
+					KeyManager: types.KeyManagerTypeCustomer,
+					KeyState:   types.KeyStateEnabled,
+				},
+			}, nil
+		},
+	}
+
+	customerManagedKeys, _, _, _ := getCustKeys(mockedKMSActionsAPI, keys, testCfg)
+
+	assert.Equal(t, len(keys), len(customerManagedKeys))
+	for i, el := range customerManagedKeys {
+		assert.Equal(t, *keys[i].KeyId, *el.KeyMetadata.KeyId)
+	}
+}
+
+func TestRunRotationResult(t *testing.T) {
+	/*
+	This test function will look at the result returned by runRotation.
+	With no keys in the account the result must still be returned, and when
+	'EnableKeyRotation' fails the key is listed as failed with the reason.
+	*/
+
+	emptyKMSActionsAPI := &KMSActionsAPIMock{
+		ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+			return &kms.ListKeysOutput{}, nil
+		},
+	}
+
+	report := runRotation(emptyKMSActionsAPI, nil, testCfg)
+
+	assert.Equal(t, 0, report.KeysScanned)
+	assert.Equal(t, 0, len(report.Failed))
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListAliasesFunc: listAliasesMock,
+		ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+			return &kms.ListKeysOutput{
+				Keys: []types.KeyListEntry{{KeyId: aws.String(keyId)}, {KeyId: aws.String("aws-key")}},
+			}, nil
+		},
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+
+			manager := types.KeyManagerTypeCustomer
+			if *params.KeyId == "aws-key" {
+				manager = types.KeyManagerTypeAws
+			}
+
+			return &kms.DescribeKeyOutput{
+				KeyMetadata: &types.KeyMetadata{KeyId: params.KeyId, KeyManager: manager, KeyState: types.KeyStateEnabled},
+			}, nil
+		},
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+			return &kms.ListResourceTagsOutput{}, nil
+		},
+		GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
+			return &kms.GetKeyRotationStatusOutput{KeyRotationEnabled: false}, nil
+		},
+		EnableKeyRotationFunc: func(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
+			return nil, errors.New("AccessDeniedException")
+		},
+	}
+
+	report = runRotation(mockedKMSActionsAPI, nil, testCfg)
+
+	assert.Equal(t, 2, report.KeysScanned)
+	assert.Equal(t, 1, report.AwsManagedSkipped)
+	assert.Equal(t, 0, report.Compliant)
+	assert.Equal(t, 0, report.Remediated)
+	assert.Equal(t, keyId, report.Failed[0].KeyId)
+	assert.Equal(t, stageEnableRotation, report.Failed[0].Stage)
+	assert.Equal(t, "AccessDeniedException", report.Failed[0].Reason)
+}
+
+func TestRotationPeriod(t *testing.T) {
+	/*
+	This test function will look at a key that rotates on the global period
+	but is tagged with a shorter period. The key must be reported as
+	non-compliant, and remediation must set the tagged period.
+	*/
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+
+			var kmsOutput kms.ListResourceTagsOutput
+			// Read json file containing the tags with a 180 day rotation period
+			data, _ := ioutil.ReadFile("test-data/list-resource-tags.json")
+
+			json.Unmarshal(data, &kmsOutput);
+			return &kmsOutput,nil;
+		},
+		GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
+			return &kms.GetKeyRotationStatusOutput{KeyRotationEnabled: true, RotationPeriodInDays: aws.Int32(365)}, nil
+		},
+		EnableKeyRotationFunc: func(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
+			return &kms.EnableKeyRotationOutput{}, nil
+		},
+	}
+
+	statuses, _ := getRotationStatus(mockedKMSActionsAPI, []kms.DescribeKeyOutput{keyDetails()}, testCfg)
+	entries := buildKeyReports(statuses)
 
-	assert.Equal(t, true, result)
+	assert.Equal(t, false, statuses[0].Compliant)
+	assert.Equal(t, int32(180), statuses[0].TargetDays)
+	assert.Equal(t, actionUpdatePeriod, entries[0].Action)
 
+	failures := setKeyRotation(mockedKMSActionsAPI, nonCompliant(statuses))
+
+	assert.Equal(t, 0, len(failures))
+	assert.Equal(t, int32(180), *mockedKMSActionsAPI.EnableKeyRotationCalls()[0].Params.RotationPeriodInDays)
+}
+
+func TestCheckExemption(t *testing.T) {
+	/*
+	This test function will look at the checkExemption function and the
+	nonCompliant filter. A permanent or future exemption exempts the key and
+	keeps it out of remediation, an expired exemption does not.
+	*/
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	permanent := checkExemption(map[string]string{defaultExemptTag: "true"}, defaultExemptTag, now)
+	future := checkExemption(map[string]string{defaultExemptTag: "2025-06-01"}, defaultExemptTag, now)
+	expired := checkExemption(map[string]string{defaultExemptTag: "2025-05-31"}, defaultExemptTag, now)
+	invalid := checkExemption(map[string]string{defaultExemptTag: "someday"}, defaultExemptTag, now)
+
+	assert.Equal(t, "exempt", permanent.String())
+	assert.Equal(t, "exempt (until 2025-06-01)", future.String())
+	assert.Equal(t, true, expired.Expired)
+	assert.Equal(t, false, expired.Exempt)
+	assert.Equal(t, "", invalid.String())
+
+	statuses := []keyStatus{
+		{Key: keyDetails(), Exemption: future},
+		{Key: keyDetails(), Exemption: expired},
+	}
+	keys := nonCompliant(statuses)
+
+	assert.Equal(t, 1, len(keys))
+	assert.Equal(t, true, keys[0].Exemption.Expired)
+	assert.Equal(t, actionExempt, buildKeyReports(statuses)[0].Action)
+}
+
+func TestClassifyKeys(t *testing.T) {
+	/*
+	This test function will look at the classifyKeys function. Only the
+	enabled symmetric key with KMS generated material is rotatable, every
+	other key is returned as ineligible with the reason.
+	*/
+
+	describe := func(id string, meta types.KeyMetadata) kms.DescribeKeyOutput {
+		meta.KeyId = aws.String(id)
+		if meta.KeyState == "" {
+			meta.KeyState = types.KeyStateEnabled
+		}
+		return kms.DescribeKeyOutput{KeyMetadata: &meta}
+	}
+
+	custKeys := []kms.DescribeKeyOutput{
+		keyDetails(),
+		describe("rsa", types.KeyMetadata{KeySpec: types.KeySpecRsa2048, KeyUsage: types.KeyUsageTypeSignVerify}),
+		describe("hmac", types.KeyMetadata{KeySpec: types.KeySpecHmac256, KeyUsage: types.KeyUsageTypeGenerateVerifyMac}),
+		describe("imported", types.KeyMetadata{KeySpec: types.KeySpecSymmetricDefault, Origin: types.OriginTypeExternal}),
+		describe("cloudhsm", types.KeyMetadata{KeySpec: types.KeySpecSymmetricDefault, Origin: types.OriginTypeAwsCloudhsm}),
+		describe("disabled", types.KeyMetadata{KeySpec: types.KeySpecSymmetricDefault, KeyState: types.KeyStateDisabled}),
+	}
 
-}
\ No newline at end of file
+	rotatable, _, ineligible := classifyKeys(custKeys)
+
+	assert.Equal(t, 1, len(rotatable))
+	assert.Equal(t, keyId, *rotatable[0].KeyMetadata.KeyId)
+	assert.Equal(t, 5, len(ineligible))
+	assert.Equal(t, eligibilityAsymmetric, ineligible[0].Reason)
+	assert.Equal(t, eligibilityHMAC, ineligible[1].Reason)
+	assert.Equal(t, eligibilityImported, ineligible[2].Reason)
+	assert.Equal(t, eligibilityCustomKeyStore, ineligible[3].Reason)
+	assert.Equal(t, eligibilityDisabled, ineligible[4].Reason)
+}
+
+func TestMultiRegionReplicas(t *testing.T) {
+	/*
+	This test function will look at runRotation with two replica keys that
+	share a primary key in another region. The replicas must be reported as
+	inheriting rotation, and the primary must be remediated once through the
+	client for its own region. The home mock has no 'EnableKeyRotation', so
+	any attempt to rotate a replica will panic the test.
+	*/
+
+	primaryArn := "arn:aws:kms:us-east-1:111122223333:key/mrk-1234abcd"
+
+	replica := func(id string) *types.KeyMetadata {
+		return &types.KeyMetadata{
+			KeyId:       aws.String(id),
+			Arn:         aws.String("arn:aws:kms:us-west-2:111122223333:key/" + id),
+			KeyManager:  types.KeyManagerTypeCustomer,
+			KeyState:    types.KeyStateEnabled,
+			MultiRegion: aws.Bool(true),
+			MultiRegionConfiguration: &types.MultiRegionConfiguration{
+				MultiRegionKeyType: types.MultiRegionKeyTypeReplica,
+				PrimaryKey:         &types.MultiRegionKey{Arn: aws.String(primaryArn), Region: aws.String("us-east-1")},
+			},
+		}
+	}
+
+	homeKMSActionsAPI := &KMSActionsAPIMock{
+		ListAliasesFunc: listAliasesMock,
+		ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+			return &kms.ListKeysOutput{
+				Keys: []types.KeyListEntry{{KeyId: aws.String("mrk-replica-a")}, {KeyId: aws.String("mrk-replica-b")}},
+			}, nil
+		},
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+			return &kms.DescribeKeyOutput{KeyMetadata: replica(*params.KeyId)}, nil
+		},
+	}
+
+	primaryKMSActionsAPI := &KMSActionsAPIMock{
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+			return &kms.DescribeKeyOutput{
+				KeyMetadata: &types.KeyMetadata{
+					KeyId:       aws.String("mrk-1234abcd"),
+					Arn:         aws.String(primaryArn),
+					KeyManager:  types.KeyManagerTypeCustomer,
+					KeyState:    types.KeyStateEnabled,
+					MultiRegion: aws.Bool(true),
+					MultiRegionConfiguration: &types.MultiRegionConfiguration{
+						MultiRegionKeyType: types.MultiRegionKeyTypePrimary,
+					},
+				},
+			}, nil
+		},
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+			return &kms.ListResourceTagsOutput{}, nil
+		},
+		GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
+			return &kms.GetKeyRotationStatusOutput{KeyRotationEnabled: false}, nil
+		},
+		EnableKeyRotationFunc: func(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
+			return &kms.EnableKeyRotationOutput{}, nil
+		},
+	}
+
+	var regions []string
+	regional := func(region string) KMSActionsAPI {
+		regions = append(regions, region)
+		return primaryKMSActionsAPI
+	}
+
+	report := runRotation(homeKMSActionsAPI, regional, testCfg)
+
+	assert.Equal(t, 2, len(report.Replicas))
+	assert.Equal(t, primaryArn, report.Replicas[1].PrimaryArn)
+	assert.Equal(t, 1, len(regions))
+	assert.Equal(t, "us-east-1", regions[0])
+	assert.Equal(t, 1, len(primaryKMSActionsAPI.DescribeKeyCalls()))
+	assert.Equal(t, 1, len(primaryKMSActionsAPI.EnableKeyRotationCalls()))
+	assert.Equal(t, 1, report.Remediated)
+	assert.Equal(t, primaryArn, report.Keys[0].Arn)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	/*
+	This test function will look at a call that stays throttled. It must be
+	made 'maxAttempts' times, with a backoff between attempts but none after
+	the last one, and return the throttling error.
+	*/
+
+	slept := 0
+	sleep = func(time.Duration) { slept++ }
+	defer func() { sleep = time.Sleep }()
+
+	calls := 0
+	err := withRetry(func() error {
+		calls++
+		return &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+	})
+
+	assert.Assert(t, isThrottle(err))
+	assert.Equal(t, maxAttempts, calls)
+	assert.Equal(t, maxAttempts-1, slept)
+}
+
+func TestPerKeyErrorsAndRetries(t *testing.T) {
+	/*
+	This test function will look at the error handling of the workflow.
+	'DescribeKey' is throttled twice before it succeeds for one key and fails
+	outright for another, 'GetKeyRotationStatus' fails for a third key. The
+	run must not panic or abort, and every failed key must be listed with the
+	stage it failed in.
+	*/
+
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	throttled := 0
+	throttle := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListAliasesFunc: listAliasesMock,
+		ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+			return &kms.ListKeysOutput{
+				Keys: []types.KeyListEntry{{KeyId: aws.String("throttled")}, {KeyId: aws.String("missing")}, {KeyId: aws.String("no-status")}},
+			}, nil
+		},
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+
+			switch *params.KeyId {
+			case "throttled":
+				if throttled < 2 {
+					throttled++
+					return nil, throttle
+				}
+			case "missing":
+				return nil, &smithy.GenericAPIError{Code: "NotFoundException", Message: "key not found"}
+			}
+
+			return &kms.DescribeKeyOutput{
+				KeyMetadata: &types.KeyMetadata{KeyId: params.KeyId, KeyManager: types.KeyManagerTypeCustomer, KeyState: types.KeyStateEnabled},
+			}, nil
+		},
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+			return &kms.ListResourceTagsOutput{}, nil
+		},
+		GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
+
+			if *params.KeyId == "no-status" {
+				return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+			}
+			return &kms.GetKeyRotationStatusOutput{KeyRotationEnabled: false}, nil
+		},
+		EnableKeyRotationFunc: func(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
+			return &kms.EnableKeyRotationOutput{}, nil
+		},
+	}
+
+	report := runRotation(mockedKMSActionsAPI, nil, Config{Mode: modeRemediate, Concurrency: 1, RotationPeriodDays: 365})
+
+	assert.Equal(t, 5, len(mockedKMSActionsAPI.DescribeKeyCalls()))
+	assert.Equal(t, 1, report.Remediated)
+	assert.Equal(t, "throttled", *mockedKMSActionsAPI.EnableKeyRotationCalls()[0].Params.KeyId)
+	assert.Equal(t, 2, len(report.Failed))
+	assert.Equal(t, "missing", report.Failed[0].KeyId)
+	assert.Equal(t, stageDescribeKey, report.Failed[0].Stage)
+	assert.Equal(t, "no-status", report.Failed[1].KeyId)
+	assert.Equal(t, stageRotationStatus, report.Failed[1].Stage)
+}
+
+func TestOnDemandRotation(t *testing.T) {
+	/*
+	This test function will look at the on-demand rotation mode. The keys
+	already rotate yearly but their material is years old. With a quota of
+	one on-demand rotation the key is only reported as having reached it,
+	with the default quota every stale key is rotated on demand.
+	*/
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListAliasesFunc: listAliasesMock,
+		ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+			return &kms.ListKeysOutput{
+				Keys: []types.KeyListEntry{{KeyId: aws.String(keyId)}, {KeyId: aws.String("quota-used")}},
+			}, nil
+		},
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+			return &kms.DescribeKeyOutput{
+				KeyMetadata: &types.KeyMetadata{
+					KeyId:        params.KeyId,
+					KeyManager:   types.KeyManagerTypeCustomer,
+					KeyState:     types.KeyStateEnabled,
+					CreationDate: aws.Time(time.Date(2017, 6, 30, 21, 44, 32, 0, time.UTC)),
+				},
+			}, nil
+		},
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+			return &kms.ListResourceTagsOutput{}, nil
+		},
+		GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
+			return &kms.GetKeyRotationStatusOutput{KeyRotationEnabled: true, RotationPeriodInDays: aws.Int32(365)}, nil
+		},
+		ListKeyRotationsFunc: func(ctx context.Context, params *kms.ListKeyRotationsInput, optFns ...func(*kms.Options)) (*kms.ListKeyRotationsOutput, error) {
+
+			var kmsOutput kms.ListKeyRotationsOutput
+			// Read json file containing one automatic and one on-demand rotation
+			data, _ := ioutil.ReadFile("test-data/list-key-rotations.json")
+
+			json.Unmarshal(data, &kmsOutput);
+			return &kmsOutput,nil;
+		},
+		RotateKeyOnDemandFunc: func(ctx context.Context, params *kms.RotateKeyOnDemandInput, optFns ...func(*kms.Options)) (*kms.RotateKeyOnDemandOutput, error) {
+			return &kms.RotateKeyOnDemandOutput{KeyId: params.KeyId}, nil
+		},
+	}
+
+	cfg := testCfg
+	cfg.OnDemandRotation = true
+	cfg.MaxMaterialAgeDays = 365
+	cfg.OnDemandQuota = 1
+
+	statuses, _ := getRotationStatus(mockedKMSActionsAPI, []kms.DescribeKeyOutput{keyDetails()}, cfg)
+	assert.Equal(t, onDemandQuotaReached, statuses[0].OnDemand)
+	assert.Equal(t, 1, statuses[0].Material.OnDemandRotations)
+	assert.Assert(t, statuses[0].Material.AgeDays > 365)
+
+	cfg.OnDemandQuota = defaultOnDemandQuota
+	report := runRotation(mockedKMSActionsAPI, nil, cfg)
+
+	assert.Equal(t, 0, report.Remediated)
+	assert.Equal(t, 2, report.RotatedOnDemand)
+	assert.Equal(t, onDemandRotate, report.Keys[0].OnDemand)
+	assert.Equal(t, 2, len(mockedKMSActionsAPI.RotateKeyOnDemandCalls()))
+}
+
+func TestMaterialLookupFailure(t *testing.T) {
+	/*
+	This test function will look at a key whose material cannot be read in the
+	on-demand rotation mode. The 'ListKeyRotations' failure must be reported,
+	and the key must still have rotation enabled and be in the report.
+	*/
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListAliasesFunc: listAliasesMock,
+		ListKeysFunc: func(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+			return &kms.ListKeysOutput{Keys: []types.KeyListEntry{{KeyId: aws.String(keyId)}}}, nil
+		},
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+			key := keyDetails()
+			return &key, nil
+		},
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+			return &kms.ListResourceTagsOutput{}, nil
+		},
+		GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
+			return &kms.GetKeyRotationStatusOutput{KeyRotationEnabled: false}, nil
+		},
+		ListKeyRotationsFunc: func(ctx context.Context, params *kms.ListKeyRotationsInput, optFns ...func(*kms.Options)) (*kms.ListKeyRotationsOutput, error) {
+			return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+		},
+		EnableKeyRotationFunc: func(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
+			return &kms.EnableKeyRotationOutput{}, nil
+		},
+	}
+
+	cfg := testCfg
+	cfg.OnDemandRotation = true
+	cfg.MaxMaterialAgeDays = 365
+	cfg.OnDemandQuota = defaultOnDemandQuota
+
+	report := runRotation(mockedKMSActionsAPI, nil, cfg)
+
+	assert.Equal(t, 1, len(report.Failed))
+	assert.Equal(t, stageListRotations, report.Failed[0].Stage)
+	assert.Equal(t, 1, report.Remediated)
+	assert.Equal(t, 1, len(report.Keys))
+	assert.Equal(t, actionEnableRotation, report.Keys[0].Action)
+	assert.Equal(t, "", report.Keys[0].OnDemand)
+	assert.Equal(t, 1, len(mockedKMSActionsAPI.EnableKeyRotationCalls()))
+}
+
+func TestEventKeyId(t *testing.T) {
+	/*
+	This test function will look at the eventKeyId function. The key is read
+	from the request of a 'DisableKeyRotation' event and from the response of
+	a 'CreateKey' event, a scheduled event is not a key event and an event
+	that is not handled has no key to check.
+	*/
+
+	readEvent := func(file string) RotationEvent {
+		var event RotationEvent
+		// Read json file containing an example EventBridge event
+		data, _ := ioutil.ReadFile(file)
+
+		json.Unmarshal(data, &event);
+		return event
+	}
+
+	id, isKeyEvent, err := eventKeyId(readEvent("test-data/event-disable-key-rotation.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, true, isKeyEvent)
+	assert.Equal(t, keyId, id)
+
+	id, _, _ = eventKeyId(readEvent("test-data/event-create-key.json"))
+	assert.Equal(t, "0987dcba-09fe-87dc-65ba-ab0987654321", id)
+
+	_, isKeyEvent, err = eventKeyId(RotationEvent{Source: "aws.events", DetailType: "Scheduled Event"})
+	assert.NilError(t, err)
+	assert.Equal(t, false, isKeyEvent)
+
+	id, isKeyEvent, err = eventKeyId(RotationEvent{Source: sourceKMS, DetailType: detailTypeAPICall, Detail: json.RawMessage(`{"eventName": "Encrypt"}`)})
+	assert.NilError(t, err)
+	assert.Equal(t, true, isKeyEvent)
+	assert.Equal(t, "", id)
+}
+
+func TestRunKeyRotation(t *testing.T) {
+	/*
+	This test function will look at runKeyRotation for the key from an event.
+	The mock has no 'ListKeys', so a full account sweep will panic the test,
+	and only the key from the event is set to rotate.
+	*/
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListAliasesFunc: listAliasesMock,
+		DescribeKeyFunc: func(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+
+			var kmsOutput kms.DescribeKeyOutput
+			data, _ := ioutil.ReadFile("test-data/key-details.json")
+
+			json.Unmarshal(data, &kmsOutput);
+			return &kmsOutput,nil;
+		},
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+			return &kms.ListResourceTagsOutput{}, nil
+		},
+		GetKeyRotationStatusFunc: func(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error) {
+			return &kms.GetKeyRotationStatusOutput{KeyRotationEnabled: false}, nil
+		},
+		EnableKeyRotationFunc: func(ctx context.Context, params *kms.EnableKeyRotationInput, optFns ...func(*kms.Options)) (*kms.EnableKeyRotationOutput, error) {
+			return &kms.EnableKeyRotationOutput{}, nil
+		},
+	}
+
+	report := runKeyRotation(mockedKMSActionsAPI, nil, keyId, testCfg)
+
+	assert.Equal(t, 1, report.KeysScanned)
+	assert.Equal(t, 1, report.Remediated)
+	assert.Equal(t, keyId, *mockedKMSActionsAPI.DescribeKeyCalls()[0].Params.KeyId)
+	assert.Equal(t, 1, len(mockedKMSActionsAPI.EnableKeyRotationCalls()))
+}
+
+func TestAuditKeyPolicies(t *testing.T) {
+	/*
+	This test function will look at the auditKeyPolicies function. The
+	example policy grants to its own account, to "*" with no condition and
+	to two other accounts through S3, one of which is trusted. Only the
+	wildcard and the untrusted account must be reported.
+	*/
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListKeyPoliciesFunc: func(ctx context.Context, params *kms.ListKeyPoliciesInput, optFns ...func(*kms.Options)) (*kms.ListKeyPoliciesOutput, error) {
+			return &kms.ListKeyPoliciesOutput{PolicyNames: []string{"default"}}, nil
+		},
+		GetKeyPolicyFunc: func(ctx context.Context, params *kms.GetKeyPolicyInput, optFns ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error) {
+
+			// Read json file containing an example key policy
+			data, _ := ioutil.ReadFile("test-data/key-policy.json")
+			return &kms.GetKeyPolicyOutput{Policy: aws.String(string(data)), PolicyName: params.PolicyName}, nil
+		},
+	}
+
+	cfg := testCfg
+	cfg.TrustedAccounts = map[string]bool{"444455556666": true}
+
+	findings, failures := auditKeyPolicies(mockedKMSActionsAPI, []kms.DescribeKeyOutput{keyDetails()}, cfg)
+
+	assert.Equal(t, 0, len(failures))
+	assert.Equal(t, 3, len(findings))
+	assert.Equal(t, issueWildcardPrincipal, findings[0].Issue)
+	assert.Equal(t, issueMissingCondition, findings[1].Issue)
+	assert.Equal(t, "Allow everyone", findings[1].Sid)
+	assert.Equal(t, issueCrossAccountPrincipal, findings[2].Issue)
+	assert.DeepEqual(t, []string{"arn:aws:iam::999999999999:root"}, findings[2].Principals)
+}
+
+func TestGrantInventory(t *testing.T) {
+	/*
+	This test function will look at the auditGrants function. The example
+	key has a grant to its own account, a grant to another account and a
+	grant older than the max age. With revocation set for stale grants only
+	the old grant is removed, and when RevokeGrant is denied it is retired.
+	*/
+
+	var revoked, retired []string
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListGrantsFunc: func(ctx context.Context, params *kms.ListGrantsInput, optFns ...func(*kms.Options)) (*kms.ListGrantsOutput, error) {
+
+			// Read json file containing the example grants
+			data, _ := ioutil.ReadFile("test-data/list-grants.json")
+			var grants kms.ListGrantsOutput;
+			json.Unmarshal(data, &grants);
+			return &grants, nil
+		},
+		RevokeGrantFunc: func(ctx context.Context, params *kms.RevokeGrantInput, optFns ...func(*kms.Options)) (*kms.RevokeGrantOutput, error) {
+			revoked = append(revoked, *params.GrantId)
+			return nil, &smithy.GenericAPIError{Code: "AccessDeniedException"}
+		},
+		RetireGrantFunc: func(ctx context.Context, params *kms.RetireGrantInput, optFns ...func(*kms.Options)) (*kms.RetireGrantOutput, error) {
+			retired = append(retired, *params.GrantId)
+			return &kms.RetireGrantOutput{}, nil
+		},
+	}
+
+	cfg := testCfg
+	cfg.GrantMaxAgeDays = 3650
+	cfg.GrantRevokeOn = map[string]bool{grantStale: true}
+	cfg.OrgAccounts = map[string]bool{"846764612917": true, "444455556666": true}
+
+	grants, removed, failures := auditGrants(mockedKMSActionsAPI, []kms.DescribeKeyOutput{keyDetails()}, cfg)
+
+	assert.Equal(t, 0, len(failures))
+	assert.Equal(t, 3, len(grants))
+	assert.DeepEqual(t, []string{}, grants[0].Flags)
+	assert.DeepEqual(t, []string{grantOutsideOrg}, grants[1].Flags)
+	assert.DeepEqual(t, []string{grantStale}, grants[2].Flags)
+
+	assert.Equal(t, 1, len(removed))
+	assert.Equal(t, grants[2].GrantId, removed[0].GrantId)
+	assert.Equal(t, grantMethodRetire, removed[0].Method)
+	assert.DeepEqual(t, []string{grants[2].GrantId}, revoked)
+	assert.DeepEqual(t, []string{grants[2].GrantId}, retired)
+
+	// the inventory is report only in audit mode
+	revoked = nil
+	cfg.Mode = modeAudit
+	_, removed, _ = auditGrants(mockedKMSActionsAPI, []kms.DescribeKeyOutput{keyDetails()}, cfg)
+	assert.Equal(t, 0, len(removed))
+	assert.Equal(t, 0, len(revoked))
+
+	// no grant is flagged as outside when the organization accounts are not known
+	cfg.OrgAccounts = nil
+	grants, _, _ = auditGrants(mockedKMSActionsAPI, []kms.DescribeKeyOutput{keyDetails()}, cfg)
+	assert.DeepEqual(t, []string{}, grants[1].Flags)
+}
+
+func TestOrgAccounts(t *testing.T) {
+	/*
+	This test function will look at the orgAccounts function. Every page of
+	'ListAccounts' must be read, and a failed lookup must be explained in the
+	error of the report.
+	*/
+
+	mockedOrganizationsAPI := &OrganizationsAPIMock{
+		ListAccountsFunc: func(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error) {
+			if params.NextToken == nil {
+				return &organizations.ListAccountsOutput{
+					Accounts:  []orgtypes.Account{{Id: aws.String("846764612917")}},
+					NextToken: aws.String("page2"),
+				}, nil
+			}
+			return &organizations.ListAccountsOutput{Accounts: []orgtypes.Account{{Id: aws.String("444455556666")}}}, nil
+		},
+	}
+
+	accounts, err := orgAccounts(mockedOrganizationsAPI)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]bool{"846764612917": true, "444455556666": true}, accounts)
+
+	report := withOrgError(Report{Error: "listing keys failed"}, errors.New("AccessDeniedException"))
+	assert.Equal(t, "listing keys failed; listing organization accounts failed, grants are not checked for principals outside the organization: AccessDeniedException", report.Error)
+	assert.Equal(t, "", withOrgError(Report{}, nil).Error)
+}
+
+func TestAliasScope(t *testing.T) {
+	/*
+	This test function will look at the scopeKeys function. The example key
+	list has a payments key, a sandbox key and a key with no alias. The key
+	with no alias must always be reported as unowned, and only be checked
+	when no include patterns are set.
+	*/
+
+	data, _ := ioutil.ReadFile("test-data/kms-key-list.json")
+	var keys kms.ListKeysOutput;
+	json.Unmarshal(data, &keys);
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListAliasesFunc: listAliasesMock,
+	}
+
+	aliases, err := listAliases(mockedKMSActionsAPI)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"alias/payments-api", "alias/payments-batch"}, aliases[keyId])
+	assert.Equal(t, 3, len(aliases))
+
+	cfg := testCfg
+	scoped, unowned := scopeKeys(keys.Keys, aliases, cfg)
+	assert.Equal(t, 3, len(scoped))
+	assert.Equal(t, 1, len(unowned))
+	assert.Equal(t, "1a2b3c4d-5e6f-1a2b-3c4d-5e6f1a2b3c4d", unowned[0].KeyId)
+
+	cfg.IncludeAliases = []string{"alias/payments-*", "alias/sandbox-*"}
+	cfg.ExcludeAliases = []string{"alias/sandbox-*"}
+	scoped, unowned = scopeKeys(keys.Keys, aliases, cfg)
+	assert.Equal(t, 1, len(scoped))
+	assert.Equal(t, keyId, *scoped[0].KeyId)
+	assert.Equal(t, 1, len(unowned))
+
+	// the key from an event is matched by the ID in its ARN
+	event := []types.KeyListEntry{{KeyId: aws.String("arn:aws:kms:us-west-2:111122223333:key/" + keyId)}}
+	scoped, _ = scopeKeys(event, aliases, cfg)
+	assert.Equal(t, 1, len(scoped))
+}
+
+func TestPendingDeletion(t *testing.T) {
+	/*
+	This test function will look at the checkPendingDeletion function. Of two
+	keys pending deletion only the one tagged as protected must have its
+	deletion cancelled and be re-enabled, and nothing is changed in audit mode.
+	*/
+
+	deletionDate := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	pending := func(id string) kms.DescribeKeyOutput {
+		return kms.DescribeKeyOutput{KeyMetadata: &types.KeyMetadata{
+			KeyId:        aws.String(id),
+			Arn:          aws.String("arn:aws:kms:us-west-2:111122223333:key/" + id),
+			KeyManager:   types.KeyManagerTypeCustomer,
+			KeyState:     types.KeyStatePendingDeletion,
+			DeletionDate: &deletionDate,
+		}}
+	}
+
+	var cancelled, enabled []string
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		ListResourceTagsFunc: func(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error) {
+			if *params.KeyId == "protected-key" {
+				return &kms.ListResourceTagsOutput{Tags: []types.Tag{{TagKey: aws.String(defaultProtectTag), TagValue: aws.String("true")}}}, nil
+			}
+			return &kms.ListResourceTagsOutput{}, nil
+		},
+		CancelKeyDeletionFunc: func(ctx context.Context, params *kms.CancelKeyDeletionInput, optFns ...func(*kms.Options)) (*kms.CancelKeyDeletionOutput, error) {
+			cancelled = append(cancelled, *params.KeyId)
+			return &kms.CancelKeyDeletionOutput{KeyId: params.KeyId}, nil
+		},
+		EnableKeyFunc: func(ctx context.Context, params *kms.EnableKeyInput, optFns ...func(*kms.Options)) (*kms.EnableKeyOutput, error) {
+			enabled = append(enabled, *params.KeyId)
+			return &kms.EnableKeyOutput{}, nil
+		},
+	}
+
+	cfg := testCfg
+	cfg.ProtectTag = defaultProtectTag
+	cfg.DeletionGuard = true
+
+	keys, count, failures := checkPendingDeletion(mockedKMSActionsAPI, []kms.DescribeKeyOutput{pending("protected-key"), pending("other-key")}, cfg)
+
+	assert.Equal(t, 0, len(failures))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "2026-11-01T00:00:00Z", keys[0].DeletionDate)
+	assert.Equal(t, true, keys[0].Protected)
+	assert.Equal(t, deletionCancel, keys[0].Action)
+	assert.Equal(t, false, keys[1].Protected)
+	assert.Equal(t, deletionNone, keys[1].Action)
+	assert.DeepEqual(t, []string{"protected-key"}, cancelled)
+	assert.DeepEqual(t, []string{"protected-key"}, enabled)
+
+	cancelled = nil
+	cfg.Mode = modeAudit
+	keys, count, _ = checkPendingDeletion(mockedKMSActionsAPI, []kms.DescribeKeyOutput{pending("protected-key")}, cfg)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, deletionCancel, keys[0].Action)
+	assert.Equal(t, 0, len(cancelled))
+}
+
+func TestProvenanceTags(t *testing.T) {
+	/*
+	This test function will look at the tagRemediated function. Only the key
+	that was set to rotate is tagged, with the configured tag keys, and a
+	tagging error is returned as a failure for the key.
+	*/
+
+	var tagged []*kms.TagResourceInput
+
+	mockedKMSActionsAPI := &KMSActionsAPIMock{
+		TagResourceFunc: func(ctx context.Context, params *kms.TagResourceInput, optFns ...func(*kms.Options)) (*kms.TagResourceOutput, error) {
+			tagged = append(tagged, params)
+			return nil, &smithy.GenericAPIError{Code: "AccessDeniedException"}
+		},
+	}
+
+	cfg := testCfg
+	cfg.RemediatedByTag = "team:changed-by"
+	cfg.RemediatedAtTag = defaultRemediatedAtTag
+	cfg.ControlIdTag = defaultControlIdTag
+	cfg.RemediatedBy = "cmk-rotation"
+	cfg.ControlId = defaultControlId
+
+	failedKey := kms.DescribeKeyOutput{KeyMetadata: &types.KeyMetadata{KeyId: aws.String("failed-key")}}
+	statuses := []keyStatus{{Key: keyDetails()}, {Key: failedKey}}
+	failed := []KeyFailure{{KeyId: "failed-key", Stage: stageEnableRotation}}
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	failures := tagRemediated(mockedKMSActionsAPI, remediatedKeys(statuses, failed), cfg, now)
+
+	assert.Equal(t, 1, len(tagged))
+	assert.Equal(t, keyId, *tagged[0].KeyId)
+	assert.Equal(t, "team:changed-by", *tagged[0].Tags[0].TagKey)
+	assert.Equal(t, "cmk-rotation", *tagged[0].Tags[0].TagValue)
+	assert.Equal(t, "2026-10-17T09:30:00Z", *tagged[0].Tags[1].TagValue)
+	assert.Equal(t, "KMS.4", *tagged[0].Tags[2].TagValue)
+
+	assert.Equal(t, 1, len(failures))
+	assert.Equal(t, stageTagResource, failures[0].Stage)
+}
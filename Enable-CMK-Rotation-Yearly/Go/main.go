@@ -2,14 +2,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
-	"os"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 )
 
 // interface that implments all of the AWS API calls needed
@@ -20,159 +23,611 @@ type KMSActionsAPI interface {
 	GetKeyRotationStatus(ctx context.Context, params *kms.GetKeyRotationStatusInput, optFns ...func(*kms.Options)) (*kms.GetKeyRotationStatusOutput, error)
 	DescribeKey(ctx context.Context, params *kms.DescribeKeyInput, optFns ...func(*kms.Options)) (*kms.DescribeKeyOutput, error)
 	ListKeys(ctx context.Context, params *kms.ListKeysInput, optFns ...func(*kms.Options)) (*kms.ListKeysOutput, error)
+	ListResourceTags(ctx context.Context, params *kms.ListResourceTagsInput, optFns ...func(*kms.Options)) (*kms.ListResourceTagsOutput, error)
+	ListKeyRotations(ctx context.Context, params *kms.ListKeyRotationsInput, optFns ...func(*kms.Options)) (*kms.ListKeyRotationsOutput, error)
+	RotateKeyOnDemand(ctx context.Context, params *kms.RotateKeyOnDemandInput, optFns ...func(*kms.Options)) (*kms.RotateKeyOnDemandOutput, error)
+	ListKeyPolicies(ctx context.Context, params *kms.ListKeyPoliciesInput, optFns ...func(*kms.Options)) (*kms.ListKeyPoliciesOutput, error)
+	GetKeyPolicy(ctx context.Context, params *kms.GetKeyPolicyInput, optFns ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error)
+	ListGrants(ctx context.Context, params *kms.ListGrantsInput, optFns ...func(*kms.Options)) (*kms.ListGrantsOutput, error)
+	RetireGrant(ctx context.Context, params *kms.RetireGrantInput, optFns ...func(*kms.Options)) (*kms.RetireGrantOutput, error)
+	RevokeGrant(ctx context.Context, params *kms.RevokeGrantInput, optFns ...func(*kms.Options)) (*kms.RevokeGrantOutput, error)
+	ListAliases(ctx context.Context, params *kms.ListAliasesInput, optFns ...func(*kms.Options)) (*kms.ListAliasesOutput, error)
+	CancelKeyDeletion(ctx context.Context, params *kms.CancelKeyDeletionInput, optFns ...func(*kms.Options)) (*kms.CancelKeyDeletionOutput, error)
+	EnableKey(ctx context.Context, params *kms.EnableKeyInput, optFns ...func(*kms.Options)) (*kms.EnableKeyOutput, error)
+	TagResource(ctx context.Context, params *kms.TagResourceInput, optFns ...func(*kms.Options)) (*kms.TagResourceOutput, error)
 
 }
 
+// run modes accepted in the 'mode' field of the invocation payload
+const (
+	modeAudit     = "audit"
+	modeRemediate = "remediate"
+)
+
+// payload the Lambda is invoked with, an empty payload or scheduled event sweeps the account in remediate mode,
+// a CloudTrail event from EventBridge checks only the key in the event
+type RotationEvent struct {
+	Mode       string          `json:"mode"`
+	Source     string          `json:"source"`
+	DetailType string          `json:"detail-type"`
+	Region     string          `json:"region"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// rotation details found for a single CMK
+type keyStatus struct {
+	Key             kms.DescribeKeyOutput
+	Tags            map[string]string
+	RotationEnabled bool
+	PeriodDays      int32
+	TargetDays      int32
+	Compliant       bool
+	Exemption       exemption
+	Material        keyMaterial
+	OnDemand        string
+}
 
-func setKeyRotation(client KMSActionsAPI, nonRotatedKeys []kms.DescribeKeyOutput) bool {
+
+func setKeyRotation(client KMSActionsAPI, nonCompliantKeys []keyStatus) []KeyFailure {
 	/*
-	Function that sets the found CMK's to rotate yearly via API call.
+	Function that sets the found CMK's to rotate on their target period via API call.
+
+	Keys that already rotate on another period are updated to the target period.
 
 	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
-	:param nonRotatedKeys: A slice of key data for non-rotated CMK's
-	:return: A bool result if all actions in function successfully run
+	:param nonCompliantKeys: A slice of rotation status for the non-compliant CMK's
+	:return: A slice of the keys that could not be set to rotate, with the stage and reason
 	*/
 
-	
-	for _, el := range nonRotatedKeys {
+	failures := []KeyFailure{}
+
+	for _, el := range nonCompliantKeys {
 		params := &kms.EnableKeyRotationInput {
-			KeyId: aws.String(*el.KeyMetadata.KeyId),
+			KeyId: aws.String(*el.Key.KeyMetadata.KeyId),
+			RotationPeriodInDays: aws.Int32(el.TargetDays),
 		}
 
-		_, err := client.EnableKeyRotation(context.TODO(), params)
+		err := withRetry(func() error {
+			_, err := client.EnableKeyRotation(context.TODO(), params)
+			return err
+		})
 
 		if err != nil {
 			log.Println(err)
+			failures = append(failures, newKeyFailure(*el.Key.KeyMetadata.KeyId, stageEnableRotation, err))
+			continue
+		}
+
+		log.Printf("Key: %v has been set to rotate every %d days successfully.\n", *el.Key.KeyMetadata.KeyId, el.TargetDays)
+	}
+	return failures
+}
+
+func getKeyTags(client KMSActionsAPI, keyId string) (map[string]string, error) {
+	/*
+	Function that reads every tag on a CMK.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param keyId: The ID of the CMK
+	:return: A map of tag key to tag value, or an error from AWS
+	*/
+
+	tags := make(map[string]string)
+	paginator := kms.NewListResourceTagsPaginator(client, &kms.ListResourceTagsInput{KeyId: aws.String(keyId)})
+
+	for paginator.HasMorePages() {
+		var resp *kms.ListResourceTagsOutput
+		err := withRetry(func() (err error) {
+			resp, err = paginator.NextPage(context.TODO())
+			return err
+		})
+
+		if err != nil {
+			return tags, err
 		}
 
-		log.Printf("Key: %v has been set to rotate successfully.\n", el.KeyMetadata.KeyId)
+		for _, tag := range resp.Tags {
+			tags[aws.ToString(tag.TagKey)] = aws.ToString(tag.TagValue)
+		}
 	}
-	return true
+	return tags, nil
 }
 
-func getRotationStatus(client KMSActionsAPI, custKeys []kms.DescribeKeyOutput) []kms.DescribeKeyOutput {
+func targetPeriod(tags map[string]string, cfg Config) int32 {
+	/*
+	Function that works out the rotation period a CMK should have.
+
+	A valid period in the cfg.RotationPeriodTag tag overrides the global cfg.RotationPeriodDays.
+
+	:param tags: The tags on the CMK
+	:param cfg: The run settings, used for the period tag and global period
+	:return: The target rotation period in days
+	*/
+
+	val, ok := tags[cfg.RotationPeriodTag]
+	if !ok {
+		return cfg.RotationPeriodDays
+	}
+
+	days, err := parseRotationPeriod(val)
+	if err != nil {
+		log.Printf("[!] Ignoring tag %v: %v\n", cfg.RotationPeriodTag, err)
+		return cfg.RotationPeriodDays
+	}
+	return days
+}
+
+func getRotationStatus(client KMSActionsAPI, custKeys []kms.DescribeKeyOutput, cfg Config) ([]keyStatus, []KeyFailure) {
 	/*
 	Function that finds the current rotation status of the CMK's.
 
+	A key is compliant when rotation is enabled on its target period, keys with a
+	current exemption tag are marked exempt. The tag and
+	status calls are spread over a pool of cfg.Concurrency workers, the returned
+	statuses keep the order of custKeys. A key whose tags or status cannot be
+	read is returned as a failure and left out of the statuses. With
+	cfg.OnDemandRotation set the age of the key material is read as well, a key
+	whose material cannot be read is returned as a failure and still kept in the
+	statuses with no on-demand decision, so its rotation is still checked.
+
 	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
 	:param custKeys: A slice of key data for KMS keys in account/region that are customer managed.
-	:return: A slice containing the key data for the non-rotated keys.
+	:param cfg: The run settings, used for the worker concurrency and rotation period
+	:return: A slice containing the rotation status for every CMK, and the keys that failed with the reason.
 	*/
 
-	var nonRotatedKeys []kms.DescribeKeyOutput
+	results := make([]*keyStatus, len(custKeys))
+	errs := make([]*KeyFailure, len(custKeys))
+
+	runWorkers(len(custKeys), cfg.Concurrency, func(i int) {
+		keyId := *custKeys[i].KeyMetadata.KeyId
+		tags, err := getKeyTags(client, keyId)
+
+		if err != nil {
+			log.Println(err)
+			failure := newKeyFailure(keyId, stageListTags, err)
+			errs[i] = &failure
+			return
+		}
 
-	for _, el := range custKeys {
 		params := &kms.GetKeyRotationStatusInput {
-			KeyId: aws.String(*el.KeyMetadata.KeyId),
+			KeyId: aws.String(keyId),
 		}
 
-		resp, err := client.GetKeyRotationStatus(context.TODO(), params)
+		var resp *kms.GetKeyRotationStatusOutput
+		err = withRetry(func() (err error) {
+			resp, err = client.GetKeyRotationStatus(context.TODO(), params)
+			return err
+		})
 
 		if err != nil {
 			log.Println(err)
+			failure := newKeyFailure(keyId, stageRotationStatus, err)
+			errs[i] = &failure
+			return
+		}
+
+		status := keyStatus{
+			Key:             custKeys[i],
+			Tags:            tags,
+			RotationEnabled: resp.KeyRotationEnabled,
+			PeriodDays:      aws.ToInt32(resp.RotationPeriodInDays),
+			TargetDays:      targetPeriod(tags, cfg),
+		}
+		status.Compliant = status.RotationEnabled && status.PeriodDays == status.TargetDays
+		status.Exemption = checkExemption(tags, cfg.ExemptTag, time.Now())
+
+		if cfg.OnDemandRotation {
+			status.Material, err = getKeyMaterial(client, custKeys[i].KeyMetadata, time.Now())
+
+			if err != nil {
+				log.Println(err)
+				failure := newKeyFailure(keyId, stageListRotations, err)
+				errs[i] = &failure
+				status.Material = keyMaterial{}
+			} else {
+				status.OnDemand = onDemandDecision(status, resp.OnDemandRotationStartDate != nil, cfg)
+			}
+		}
+
+		results[i] = &status
+	})
+
+	var statuses []keyStatus
+	failures := []KeyFailure{}
+
+	for i := range custKeys {
+		if errs[i] != nil {
+			failures = append(failures, *errs[i])
 		}
 
-		if resp.KeyRotationEnabled == false {
-			nonRotatedKeys = append(nonRotatedKeys, el)
+		if results[i] != nil {
+			statuses = append(statuses, *results[i])
 		}
 	}
-	return nonRotatedKeys
+	return statuses, failures
 }
 
-func getCustKeys(client KMSActionsAPI, keys []types.KeyListEntry) []kms.DescribeKeyOutput {
+func nonCompliant(statuses []keyStatus) []keyStatus {
+	/*
+	Function that filters the rotation statuses down to the keys needing remediation.
+
+	Exempt keys are never returned, keys with an expired exemption are.
+
+	:param statuses: A slice containing the rotation status for every CMK.
+	:return: A slice containing the rotation status for the non-compliant CMK's.
+	*/
+
+	var keys []keyStatus
+
+	for _, el := range statuses {
+		if !el.Compliant && !el.Exemption.Exempt {
+			keys = append(keys, el)
+		}
+	}
+	return keys
+}
+
+func getCustKeys(client KMSActionsAPI, keys []types.KeyListEntry, cfg Config) ([]kms.DescribeKeyOutput, []kms.DescribeKeyOutput, int, []KeyFailure) {
 	/*
 	Function that finds CMK's in the current AWS account/region.
 
+	The describe calls are spread over a pool of cfg.Concurrency workers, the
+	returned keys keep the order of keys. A key that cannot be described is
+	returned as a failure, the other keys are still checked.
+
 	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
 	:param keys: A slice containing all key data for the current AWS account/region.
-	:return: A slice containing the key data for all customer managed keys.
+	:param cfg: The run settings, used for the worker concurrency
+	:return: A slice containing the key data for all customer managed keys, the customer managed keys pending deletion, the number of AWS managed keys skipped, and the keys that failed with the reason.
 	*/
 
-	var custKeys []kms.DescribeKeyOutput
+	var custKeys, pending []kms.DescribeKeyOutput
+	awsManaged := 0
+	failures := []KeyFailure{}
+	described := make([]*kms.DescribeKeyOutput, len(keys))
+	errs := make([]error, len(keys))
 
-	for _, el := range keys {
+	runWorkers(len(keys), cfg.Concurrency, func(i int) {
 		params := &kms.DescribeKeyInput {
-			KeyId: aws.String(*el.KeyId),
+			KeyId: aws.String(*keys[i].KeyId),
 		}
 
-		resp, err := client.DescribeKey(context.TODO(), params)
-		if err != nil {
-			log.Println(err)
+		errs[i] = withRetry(func() (err error) {
+			described[i], err = client.DescribeKey(context.TODO(), params)
+			return err
+		})
+	})
+
+	for i, resp := range described {
+		if errs[i] != nil {
+			log.Println(errs[i])
+			failures = append(failures, newKeyFailure(*keys[i].KeyId, stageDescribeKey, errs[i]))
+			continue
 		}
 
-		if resp.KeyMetadata.KeyManager == "CUSTOMER" && resp.KeyMetadata.KeyState != "PendingDeletion" {
+		if resp.KeyMetadata.KeyManager != "CUSTOMER" {
+			awsManaged++
+			continue
+		}
+
+		if resp.KeyMetadata.KeyState == "PendingDeletion" {
+			pending = append(pending, *resp)
+		} else {
 			custKeys = append(custKeys, *resp)
 		}
 	}
-	return custKeys
+	return custKeys, pending, awsManaged, failures
 }
 
 
-func listKeys(client KMSActionsAPI) []types.KeyListEntry {
+func listKeys(client KMSActionsAPI) ([]types.KeyListEntry, error) {
 	/*
 	Function that obtains key data for all keys in the current AWS account/region.
 
+	Follows the 'NextMarker' of truncated responses until every page is read.
+
 	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
-	:return: A slice containing the key data for all keys in AWS account/region.
+	:return: A slice containing the key data for all keys in AWS account/region, with the keys read before any error.
 	*/
 
-	resp, err := client.ListKeys(context.TODO(), nil)
+	var keys []types.KeyListEntry
+	paginator := kms.NewListKeysPaginator(client, &kms.ListKeysInput{})
 
-	if err != nil {
-		log.Println(err)
+	for paginator.HasMorePages() {
+		var resp *kms.ListKeysOutput
+		err := withRetry(func() (err error) {
+			resp, err = paginator.NextPage(context.TODO())
+			return err
+		})
+
+		if err != nil {
+			return keys, err
+		}
+
+		keys = append(keys, resp.Keys...)
 	}
 
-	return resp.Keys
+	return keys, nil
 }
 
 
-func HandleRequest(ctx context.Context) {
+func runRotation(client KMSActionsAPI, regional regionalClients, cfg Config) (report Report) {
 	/*
-	Main handler for the Lambda that dispatches calls to functions.
+	Function that runs the rotation workflow against the keys in the account/region.
 
-	:param ctx: The default Lambda context during execution.
-	:return: None
+	The report is returned on every path, including when there is nothing to rotate.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param regional: The factory for the regional clients of multi-Region primary keys
+	:param cfg: The run settings, including the mode ('audit' or 'remediate') and worker concurrency
+	:return: A report with the run totals, the failed keys and an entry for every CMK
 	*/
 
-	// load the KMS client
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+	start := time.Now()
+	report = newReport(cfg.Mode)
+
+	defer func() {
+		report.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	// get an array of KMS keys, a listing error still checks the keys read before it
+	listOfKeys, err := listKeys(client)
+	report.KeysScanned = len(listOfKeys)
+
 	if err != nil {
-        panic("unable to load SDK config, " + err.Error())
+		log.Println(err)
+		report.Error = "listing keys failed, results are partial: " + err.Error()
 	}
-	client := kms.NewFromConfig(cfg)
-
-	// get an array of KMS keys
-	listOfKeys := listKeys(client)
 
 	if len(listOfKeys) == 0 {
 		log.Println("[!] No keys found in account.")
-		os.Exit(0)
+		return report
 	}
 
+	// limit the sweep to the keys matching the alias patterns
+	listOfKeys, ok := applyScope(client, listOfKeys, cfg, &report)
+	if !ok || len(listOfKeys) == 0 {
+		log.Println("[!] No keys in scope.")
+		return report
+	}
+
+	checkKeys(client, regional, listOfKeys, cfg, &report)
+	return report
+}
+
+
+func runKeyRotation(client KMSActionsAPI, regional regionalClients, keyId string, cfg Config) (report Report) {
+	/*
+	Function that runs the rotation workflow against the single key from a CloudTrail event.
+
+	No 'ListKeys' sweep is made, so rotation that was turned off is re-enabled
+	within seconds of the event.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param regional: The factory for the regional clients of multi-Region primary keys
+	:param keyId: The ID of the key from the event
+	:param cfg: The run settings, including the mode ('audit' or 'remediate') and worker concurrency
+	:return: A report with the run totals, the failed keys and an entry for the key
+	*/
+
+	start := time.Now()
+	report = newReport(cfg.Mode)
+
+	defer func() {
+		report.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	log.Printf("[!] Checking key %v from event.\n", keyId)
+	listOfKeys := []types.KeyListEntry{{KeyId: aws.String(keyId)}}
+	report.KeysScanned = len(listOfKeys)
+
+	listOfKeys, ok := applyScope(client, listOfKeys, cfg, &report)
+	if !ok || len(listOfKeys) == 0 {
+		log.Printf("[!] Key %v is not in scope.\n", keyId)
+		return report
+	}
+
+	checkKeys(client, regional, listOfKeys, cfg, &report)
+	return report
+}
+
+
+func checkKeys(client KMSActionsAPI, regional regionalClients, listOfKeys []types.KeyListEntry, cfg Config, report *Report) {
+	/*
+	Function that checks the listed keys and remediates them, adding the results to the report.
+
+	In audit mode the report is built without any call to 'EnableKeyRotation'.
+	Multi-Region replicas are not rotated, their primary keys are checked once
+	through a client for the primary's region.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param regional: The factory for the regional clients of multi-Region primary keys
+	:param listOfKeys: A slice containing the key data of the keys to check
+	:param cfg: The run settings, including the mode ('audit' or 'remediate') and worker concurrency
+	:param report: The report the results are added to
+	:return: None
+	*/
+
 	// get an array of CMK's
-	custKeys := getCustKeys(client, listOfKeys)
+	custKeys, pending, awsManaged, failed := getCustKeys(client, listOfKeys, cfg)
+	report.AwsManagedSkipped = awsManaged
+	report.Failed = append(report.Failed, failed...)
+
+	// report the CMK's scheduled for deletion, cancelling the deletion of protected keys
+	if len(pending) > 0 {
+		deletions, cancelled, failed := checkPendingDeletion(client, pending, cfg)
+		report.PendingDeletion = deletions
+		report.DeletionsCancelled = cancelled
+		report.Failed = append(report.Failed, failed...)
+		log.Printf("[!] %d keys pending deletion.\n", len(pending))
+	}
 
 	if len(custKeys) == 0 {
 		log.Println("[!] No customer managed keys found in account.")
-		os.Exit(0)
+		return
 	}
 
-	// get the rotation status of the CMK's
-	statusOfKeys := getRotationStatus(client, custKeys)
+	// audit the key policies of every CMK, this is report only
+	if cfg.PolicyAudit {
+		findings, failed := auditKeyPolicies(client, custKeys, cfg)
+		report.PolicyFindings = findings
+		report.Failed = append(report.Failed, failed...)
+		log.Printf("[!] %d key policy findings.\n", len(findings))
+	}
 
-	if len(statusOfKeys) == 0 {
-		log.Println("[+] All keys set to rotate in account, no action taken.")
-		os.Exit(0)
+	// inventory the grants of every CMK, removing grants that match the revocation rule
+	if cfg.GrantAudit {
+		grants, revoked, failed := auditGrants(client, custKeys, cfg)
+		report.Grants = grants
+		report.RevokedGrants = revoked
+		report.Failed = append(report.Failed, failed...)
+		log.Printf("[!] %d grants found, %d removed.\n", len(grants), len(revoked))
 	}
 
-	// set the CMK's to rotate
-	log.Printf("[!] Attempting to set keys %v to rotate.\n", statusOfKeys)
-	result := setKeyRotation(client, statusOfKeys)
+	// split out the CMK's that KMS cannot rotate and the multi-Region replicas
+	rotatableKeys, replicas, ineligible := classifyKeys(custKeys)
+	report.Ineligible = ineligible
+	report.Replicas = replicas
+
+	// the primaries of replicas from other regions are checked in their own region
+	groups := []keyGroup{{Client: client, Keys: rotatableKeys}}
+	primaryGroups, primaryIneligible, failed := remotePrimaries(regional, custKeys, replicas, cfg)
+	groups = append(groups, primaryGroups...)
+	report.Ineligible = append(report.Ineligible, primaryIneligible...)
+	report.Failed = append(report.Failed, failed...)
+
+	rotatableCount := 0
+	for _, g := range groups {
+		rotatableCount += len(g.Keys)
+	}
+
+	if rotatableCount == 0 {
+		log.Println("[!] No customer managed keys in account are eligible for rotation.")
+		return
+	}
+
+	nonCompliantCount := 0
+
+	for _, g := range groups {
+		// get the rotation status of the CMK's
+		statuses, failed := getRotationStatus(g.Client, g.Keys, cfg)
+		report.Failed = append(report.Failed, failed...)
+		statusOfKeys := nonCompliant(statuses)
+		report.Keys = append(report.Keys, buildKeyReports(statuses)...)
+		nonCompliantCount += len(statusOfKeys)
+
+		for _, el := range statuses {
+			if el.Exemption.Exempt {
+				report.Exempt++
+			} else if el.Compliant {
+				report.Compliant++
+			}
+		}
+
+		if cfg.Mode == modeAudit {
+			continue
+		}
 
-	if result {
+		// set the CMK's to rotate
+		if len(statusOfKeys) > 0 {
+			log.Printf("[!] Attempting to set %d keys to rotate.\n", len(statusOfKeys))
+			failed = setKeyRotation(g.Client, statusOfKeys)
+			report.Failed = append(report.Failed, failed...)
+			report.Remediated += len(statusOfKeys) - len(failed)
+
+			// record on the keys that they were changed by this Lambda
+			if cfg.ProvenanceTags {
+				tagFailed := tagRemediated(g.Client, remediatedKeys(statusOfKeys, failed), cfg, time.Now())
+				report.TagFailures = append(report.TagFailures, tagFailed...)
+			}
+		}
+
+		// rotate the CMK's with stale key material
+		if cfg.OnDemandRotation {
+			rotated, failed := rotateOnDemand(g.Client, statuses)
+			report.Failed = append(report.Failed, failed...)
+			report.RotatedOnDemand += rotated
+		}
+	}
+
+	if cfg.Mode == modeAudit {
+		log.Printf("[!] Audit mode, %d of %d keys would be set to rotate.\n", nonCompliantCount, rotatableCount)
+		return
+	}
+
+	if len(report.Failed) > 0 {
+		log.Printf("[!] %d keys failed, see the report for the reasons.\n", len(report.Failed))
+	} else if nonCompliantCount == 0 {
+		log.Println("[+] All keys set to rotate in account, no action taken.")
+	} else {
 		log.Println("[+] All keys set to rotate successfully.")
 	}
+}
+
+
+func HandleRequest(ctx context.Context, event RotationEvent) (Report, error) {
+	/*
+	Main handler for the Lambda that dispatches calls to functions.
+
+	:param ctx: The default Lambda context during execution.
+	:param event: The invocation payload, the 'mode' field selects audit or remediate (default),
+	  a CloudTrail event from EventBridge checks only the key in the event.
+	:return: A report of the CMK's in the account/region, or an error for an unknown mode, an unreadable event or an SDK config that cannot be loaded
+	*/
+
+	runCfg, err := loadConfig()
+	if err != nil {
+		return Report{}, err
+	}
+
+	if event.Mode != "" {
+		runCfg.Mode = event.Mode
+	}
+
+	if runCfg.Mode != modeAudit && runCfg.Mode != modeRemediate {
+		return Report{}, fmt.Errorf("unknown mode %q, expected %q or %q", runCfg.Mode, modeAudit, modeRemediate)
+	}
+
+	// load the KMS client, throttled calls are retried by withRetry and not by the SDK
+	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRetryMaxAttempts(1))
+	if err != nil {
+		report := newReport(runCfg.Mode)
+		report.Error = "unable to load SDK config, " + err.Error()
+		return report, fmt.Errorf("unable to load SDK config, %v", err)
+	}
+	var client KMSActionsAPI = kms.NewFromConfig(cfg)
+	regional := newRegionalClients(cfg)
+
+	// grants are checked against the accounts of the organization
+	var orgErr error
+	if runCfg.GrantAudit {
+		runCfg.OrgAccounts, orgErr = orgAccounts(organizations.NewFromConfig(cfg))
+		if orgErr != nil {
+			log.Println(orgErr)
+		}
+	}
+
+	keyId, isKeyEvent, err := eventKeyId(event)
+	if err != nil {
+		return Report{}, err
+	}
+
+	if !isKeyEvent {
+		return withOrgError(runRotation(client, regional, runCfg), orgErr), nil
+	}
+
+	if keyId == "" {
+		log.Println("[!] Event has no key to check, no action taken.")
+		return newReport(runCfg.Mode), nil
+	}
+
+	// the event region is used when the event was forwarded from another region
+	if event.Region != "" && event.Region != cfg.Region {
+		client = regional(event.Region)
+	}
 
+	return withOrgError(runKeyRotation(client, regional, keyId, runCfg), orgErr), nil
 }
 
 
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// returns the KMS client for a region, used to reach multi-Region primary keys outside the home region
+type regionalClients func(region string) KMSActionsAPI
+
+// CMK's that are checked and remediated through the same regional client
+type keyGroup struct {
+	Client KMSActionsAPI
+	Keys   []kms.DescribeKeyOutput
+}
+
+func newRegionalClients(cfg aws.Config) regionalClients {
+	/*
+	Function that creates a cached factory of regional KMS clients.
+
+	:param cfg: The SDK config the regional clients are built from
+	:return: A function returning the KMS client for a region, one client is built per region
+	*/
+
+	var mu sync.Mutex
+	clients := make(map[string]KMSActionsAPI)
+
+	return func(region string) KMSActionsAPI {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if client, ok := clients[region]; ok {
+			return client
+		}
+
+		client := kms.NewFromConfig(cfg, func(o *kms.Options) {
+			o.Region = region
+		})
+		clients[region] = client
+		return client
+	}
+}
+
+func isReplica(meta *types.KeyMetadata) bool {
+	/*
+	Function that checks whether a CMK is a multi-Region replica key.
+
+	:param meta: The key metadata returned by 'DescribeKey'
+	:return: A bool that is true for replica keys
+	*/
+
+	return aws.ToBool(meta.MultiRegion) &&
+		meta.MultiRegionConfiguration != nil &&
+		meta.MultiRegionConfiguration.MultiRegionKeyType == types.MultiRegionKeyTypeReplica
+}
+
+func replicaReport(meta *types.KeyMetadata) ReplicaKey {
+	/*
+	Function that builds the report entry for a multi-Region replica key.
+
+	:param meta: The key metadata returned by 'DescribeKey'
+	:return: The report entry, pointing at the primary key the rotation is inherited from
+	*/
+
+	entry := ReplicaKey{
+		KeyId:  *meta.KeyId,
+		Arn:    aws.ToString(meta.Arn),
+		Status: "inherits rotation from primary",
+	}
+
+	if primary := meta.MultiRegionConfiguration.PrimaryKey; primary != nil {
+		entry.PrimaryArn = aws.ToString(primary.Arn)
+		entry.PrimaryRegion = aws.ToString(primary.Region)
+	}
+	return entry
+}
+
+func remotePrimaries(regional regionalClients, custKeys []kms.DescribeKeyOutput, replicas []ReplicaKey, cfg Config) ([]keyGroup, []IneligibleKey, []KeyFailure) {
+	/*
+	Function that finds the primary keys of the replicas that were not part of this scan.
+
+	Rotation can only be set on the primary key, so each primary is described
+	once through a client for its own region, however many replicas it has.
+
+	:param regional: The factory for regional KMS clients, primaries are skipped when nil
+	:param custKeys: A slice of key data for the CMK's found in this account/region
+	:param replicas: The report entries for the replica keys found in this account/region
+	:param cfg: The run settings, used for the worker concurrency
+	:return: A key group per region with the rotatable primaries, the ineligible primaries, and the primaries that could not be described
+	*/
+
+	scanned := make(map[string]bool)
+	for _, el := range custKeys {
+		scanned[aws.ToString(el.KeyMetadata.Arn)] = true
+	}
+
+	byRegion := make(map[string][]types.KeyListEntry)
+	for _, el := range replicas {
+		if el.PrimaryArn == "" || scanned[el.PrimaryArn] {
+			continue
+		}
+		scanned[el.PrimaryArn] = true
+		byRegion[el.PrimaryRegion] = append(byRegion[el.PrimaryRegion], types.KeyListEntry{
+			KeyId:  aws.String(el.PrimaryArn),
+			KeyArn: aws.String(el.PrimaryArn),
+		})
+	}
+
+	regions := make([]string, 0, len(byRegion))
+	for region := range byRegion {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	var groups []keyGroup
+	ineligible := []IneligibleKey{}
+	failures := []KeyFailure{}
+
+	for _, region := range regions {
+		if regional == nil {
+			log.Printf("[!] No client for region %v, skipping %d primary keys.\n", region, len(byRegion[region]))
+			continue
+		}
+
+		client := regional(region)
+		primaries, _, _, failed := getCustKeys(client, byRegion[region], cfg)
+		rotatable, _, notRotatable := classifyKeys(primaries)
+
+		groups = append(groups, keyGroup{Client: client, Keys: rotatable})
+		ineligible = append(ineligible, notRotatable...)
+		failures = append(failures, failed...)
+	}
+	return groups, ineligible, failures
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// on-demand rotation outcomes reported for a key with stale key material
+const (
+	onDemandRotate       = "rotate-on-demand"
+	onDemandQuotaReached = "quota-reached"
+	onDemandInProgress   = "in-progress"
+)
+
+// key material details read from the rotation history of a CMK
+type keyMaterial struct {
+	AgeDays           int
+	OnDemandRotations int
+}
+
+func getKeyMaterial(client KMSActionsAPI, meta *types.KeyMetadata, now time.Time) (keyMaterial, error) {
+	/*
+	Function that works out the age of the current key material of a CMK.
+
+	The age runs from the most recent rotation, or from the key creation when
+	it has never rotated.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param meta: The key metadata returned by 'DescribeKey'
+	:param now: The time the age is measured at
+	:return: The age of the key material and the number of on-demand rotations so far, or an error from AWS
+	*/
+
+	var material keyMaterial
+	current := aws.ToTime(meta.CreationDate)
+	paginator := kms.NewListKeyRotationsPaginator(client, &kms.ListKeyRotationsInput{KeyId: meta.KeyId})
+
+	for paginator.HasMorePages() {
+		var resp *kms.ListKeyRotationsOutput
+		err := withRetry(func() (err error) {
+			resp, err = paginator.NextPage(context.TODO())
+			return err
+		})
+
+		if err != nil {
+			return material, err
+		}
+
+		for _, el := range resp.Rotations {
+			if el.RotationType == types.RotationTypeOnDemand {
+				material.OnDemandRotations++
+			}
+
+			if date := aws.ToTime(el.RotationDate); date.After(current) {
+				current = date
+			}
+		}
+	}
+
+	if !current.IsZero() {
+		material.AgeDays = int(now.Sub(current).Hours() / 24)
+	}
+	return material, nil
+}
+
+func onDemandDecision(status keyStatus, inProgress bool, cfg Config) string {
+	/*
+	Function that decides whether a CMK should be rotated on demand.
+
+	Only keys whose material is older than cfg.MaxMaterialAgeDays are rotated,
+	and never past the on-demand rotation quota or while a rotation is running.
+
+	:param status: The rotation status of the CMK, with the key material details
+	:param inProgress: Whether an on-demand rotation of the CMK is already running
+	:param cfg: The run settings, used for the age threshold and on-demand quota
+	:return: The on-demand outcome for the key, or empty when its material is not stale
+	*/
+
+	if status.Exemption.Exempt || status.Material.AgeDays <= cfg.MaxMaterialAgeDays {
+		return ""
+	}
+
+	switch {
+	case inProgress:
+		return onDemandInProgress
+	case status.Material.OnDemandRotations >= cfg.OnDemandQuota:
+		return onDemandQuotaReached
+	}
+	return onDemandRotate
+}
+
+func rotateOnDemand(client KMSActionsAPI, statuses []keyStatus) (int, []KeyFailure) {
+	/*
+	Function that starts an on-demand rotation of the CMK's with stale key material.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param statuses: A slice containing the rotation status for the CMK's, only keys marked for on-demand rotation are rotated
+	:return: The number of keys rotated, and the keys that could not be rotated with the reason
+	*/
+
+	rotated := 0
+	failures := []KeyFailure{}
+
+	for _, el := range statuses {
+		if el.OnDemand != onDemandRotate {
+			continue
+		}
+
+		keyId := *el.Key.KeyMetadata.KeyId
+		err := withRetry(func() error {
+			_, err := client.RotateKeyOnDemand(context.TODO(), &kms.RotateKeyOnDemandInput{KeyId: aws.String(keyId)})
+			return err
+		})
+
+		if err != nil {
+			log.Println(err)
+			failures = append(failures, newKeyFailure(keyId, stageRotateOnDemand, err))
+			continue
+		}
+
+		log.Printf("Key: %v material is %d days old, on-demand rotation started.\n", keyId, el.Material.AgeDays)
+		rotated++
+	}
+	return rotated, failures
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+)
+
+// interface for the Organizations calls used to find the accounts of the organization
+// provides the ability for mocks during testing
+//go:generate moq -out org_moq_test.go . OrganizationsAPI
+type OrganizationsAPI interface {
+	ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error)
+}
+
+func orgAccounts(client OrganizationsAPI) (map[string]bool, error) {
+	/*
+	Function that lists the member accounts of the organization.
+
+	'ListAccounts' can only be called from the management account or a delegated
+	administrator account of the organization.
+
+	:param client: An instantiated struct that contains methods matching the OrganizationsAPI interface
+	:return: A set of the account IDs in the organization, or an error from AWS
+	*/
+
+	accounts := make(map[string]bool)
+	paginator := organizations.NewListAccountsPaginator(client, &organizations.ListAccountsInput{})
+
+	for paginator.HasMorePages() {
+		var resp *organizations.ListAccountsOutput
+		err := withRetry(func() (err error) {
+			resp, err = paginator.NextPage(context.TODO())
+			return err
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range resp.Accounts {
+			if account.Id != nil {
+				accounts[*account.Id] = true
+			}
+		}
+	}
+	return accounts, nil
+}
+
+func withOrgError(report Report, err error) Report {
+	/*
+	Function that adds a failed organization lookup to the error of a report.
+
+	:param report: The report of the run
+	:param err: The error from listing the organization accounts, or nil
+	:return: The report, with the error explaining that no grant was checked for principals outside the organization
+	*/
+
+	if err == nil {
+		return report
+	}
+
+	msg := "listing organization accounts failed, grants are not checked for principals outside the organization: " + err.Error()
+	if report.Error != "" {
+		msg = report.Error + "; " + msg
+	}
+	report.Error = msg
+	return report
+}
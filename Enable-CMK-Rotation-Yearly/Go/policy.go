@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// issues reported for a key policy statement
+const (
+	issueWildcardPrincipal     = "wildcard-principal"
+	issueCrossAccountPrincipal = "cross-account-principal"
+	issueMissingCondition      = "missing-condition"
+)
+
+// condition keys that scope a broad grant to a service or to the organization
+var scopingConditionKeys = []string{"kms:viaservice", "aws:principalorgid"}
+
+// policy fields that can be either a single string or a list of strings
+type stringOrSlice []string
+
+func (s *stringOrSlice) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = []string{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// principal of a statement, either "*" or a map such as {"AWS": [...], "Service": [...]}
+type policyPrincipal struct {
+	Wildcard bool
+	AWS      stringOrSlice
+}
+
+func (p *policyPrincipal) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		p.Wildcard = single == "*"
+		return nil
+	}
+
+	var principals struct {
+		AWS stringOrSlice `json:"AWS"`
+	}
+	if err := json.Unmarshal(data, &principals); err != nil {
+		return err
+	}
+
+	for _, el := range principals.AWS {
+		if el == "*" {
+			p.Wildcard = true
+		} else {
+			p.AWS = append(p.AWS, el)
+		}
+	}
+	return nil
+}
+
+// statement of a key policy, only the fields the audit looks at
+type policyStatement struct {
+	Sid       string                                `json:"Sid"`
+	Effect    string                                `json:"Effect"`
+	Principal policyPrincipal                       `json:"Principal"`
+	Action    stringOrSlice                         `json:"Action"`
+	Condition map[string]map[string]json.RawMessage `json:"Condition"`
+}
+
+// key policy document, the statement can be a single object or a list
+type policyDocument struct {
+	Statement []policyStatement
+}
+
+func (d *policyDocument) UnmarshalJSON(data []byte) error {
+	var doc struct {
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	var single policyStatement
+	if err := json.Unmarshal(doc.Statement, &single); err == nil {
+		d.Statement = []policyStatement{single}
+		return nil
+	}
+	return json.Unmarshal(doc.Statement, &d.Statement)
+}
+
+func principalAccount(principal string) string {
+	/*
+	Function that finds the account ID of an AWS principal.
+
+	:param principal: An account ID or an IAM ARN such as arn:aws:iam::111122223333:root
+	:return: The 12 digit account ID, or empty when it cannot be found
+	*/
+
+	if parts := strings.Split(principal, ":"); len(parts) >= 5 {
+		return parts[4]
+	}
+	if len(principal) == 12 {
+		return principal
+	}
+	return ""
+}
+
+func hasScopingCondition(stmt policyStatement) bool {
+	/*
+	Function that checks whether a statement is limited by a kms:ViaService or aws:PrincipalOrgID condition.
+
+	:param stmt: The policy statement
+	:return: A bool that is true when one of the scoping condition keys is used
+	*/
+
+	for _, keys := range stmt.Condition {
+		for key := range keys {
+			for _, el := range scopingConditionKeys {
+				if strings.EqualFold(key, el) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func auditPolicy(keyId string, policyName string, policy string, ownAccount string, cfg Config) ([]PolicyFinding, error) {
+	/*
+	Function that checks a key policy document for over-permissive principals.
+
+	Allow statements are reported when they grant to "*" or to an account that is
+	neither the key's own account nor in cfg.TrustedAccounts. Those statements are
+	also reported when they have no kms:ViaService or aws:PrincipalOrgID condition.
+
+	:param keyId: The ID of the key the policy is attached to
+	:param policyName: The name of the key policy
+	:param policy: The key policy document as JSON
+	:param ownAccount: The account ID that owns the key
+	:param cfg: The run settings, used for the trusted accounts allowlist
+	:return: A slice with a finding per issue found, or an error when the policy cannot be parsed
+	*/
+
+	var doc policyDocument
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse key policy %v, %v", policyName, err)
+	}
+
+	var findings []PolicyFinding
+
+	for _, stmt := range doc.Statement {
+		if stmt.Effect != "Allow" {
+			continue
+		}
+
+		finding := PolicyFinding{
+			KeyId:      keyId,
+			PolicyName: policyName,
+			Sid:        stmt.Sid,
+			Actions:    stmt.Action,
+		}
+
+		var issues []string
+		if stmt.Principal.Wildcard {
+			issues = append(issues, issueWildcardPrincipal)
+			finding.Principals = append(finding.Principals, "*")
+		}
+
+		var external []string
+		for _, el := range stmt.Principal.AWS {
+			account := principalAccount(el)
+			if account != ownAccount && !cfg.TrustedAccounts[account] {
+				external = append(external, el)
+			}
+		}
+		if len(external) > 0 {
+			issues = append(issues, issueCrossAccountPrincipal)
+			finding.Principals = append(finding.Principals, external...)
+		}
+
+		if len(issues) > 0 && !hasScopingCondition(stmt) {
+			issues = append(issues, issueMissingCondition)
+		}
+
+		for _, issue := range issues {
+			entry := finding
+			entry.Issue = issue
+			findings = append(findings, entry)
+		}
+	}
+	return findings, nil
+}
+
+func getKeyPolicies(client KMSActionsAPI, keyId string) (map[string]string, error) {
+	/*
+	Function that reads every key policy attached to a CMK.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param keyId: The ID of the CMK
+	:return: A map of policy name to policy document, or an error from AWS
+	*/
+
+	policies := make(map[string]string)
+	paginator := kms.NewListKeyPoliciesPaginator(client, &kms.ListKeyPoliciesInput{KeyId: aws.String(keyId)})
+
+	for paginator.HasMorePages() {
+		var resp *kms.ListKeyPoliciesOutput
+		err := withRetry(func() (err error) {
+			resp, err = paginator.NextPage(context.TODO())
+			return err
+		})
+
+		if err != nil {
+			return policies, err
+		}
+
+		for _, name := range resp.PolicyNames {
+			var policy *kms.GetKeyPolicyOutput
+			err := withRetry(func() (err error) {
+				policy, err = client.GetKeyPolicy(context.TODO(), &kms.GetKeyPolicyInput{KeyId: aws.String(keyId), PolicyName: aws.String(name)})
+				return err
+			})
+
+			if err != nil {
+				return policies, err
+			}
+			policies[name] = aws.ToString(policy.Policy)
+		}
+	}
+	return policies, nil
+}
+
+func auditKeyPolicies(client KMSActionsAPI, custKeys []kms.DescribeKeyOutput, cfg Config) ([]PolicyFinding, []KeyFailure) {
+	/*
+	Function that audits the key policies of the CMK's, no policy is ever changed.
+
+	The policy calls are spread over a pool of cfg.Concurrency workers, the
+	findings keep the order of custKeys.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param custKeys: A slice of key data for KMS keys in account/region that are customer managed.
+	:param cfg: The run settings, used for the worker concurrency and trusted accounts
+	:return: A slice of the policy findings, and the keys whose policy could not be read with the reason
+	*/
+
+	results := make([][]PolicyFinding, len(custKeys))
+	errs := make([]error, len(custKeys))
+	stages := make([]string, len(custKeys))
+
+	runWorkers(len(custKeys), cfg.Concurrency, func(i int) {
+		meta := custKeys[i].KeyMetadata
+		policies, err := getKeyPolicies(client, *meta.KeyId)
+
+		if err != nil {
+			errs[i], stages[i] = err, stageGetKeyPolicy
+			return
+		}
+
+		names := make([]string, 0, len(policies))
+		for name := range policies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			findings, err := auditPolicy(*meta.KeyId, name, policies[name], aws.ToString(meta.AWSAccountId), cfg)
+
+			if err != nil {
+				errs[i], stages[i] = err, stageParseKeyPolicy
+				return
+			}
+			results[i] = append(results[i], findings...)
+		}
+	})
+
+	findings := []PolicyFinding{}
+	failures := []KeyFailure{}
+
+	for i, el := range custKeys {
+		if errs[i] != nil {
+			log.Println(errs[i])
+			failures = append(failures, newKeyFailure(*el.KeyMetadata.KeyId, stages[i], errs[i]))
+			continue
+		}
+		findings = append(findings, results[i]...)
+	}
+	return findings, failures
+}
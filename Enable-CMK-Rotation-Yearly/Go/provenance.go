@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+func remediatedKeys(statuses []keyStatus, failed []KeyFailure) []keyStatus {
+	/*
+	Function that finds the keys that were set to rotate successfully.
+
+	:param statuses: A slice of rotation status for the keys that were remediated
+	:param failed: The keys that could not be set to rotate
+	:return: A slice of rotation status for the keys not in failed
+	*/
+
+	failedKeys := make(map[string]bool)
+	for _, el := range failed {
+		failedKeys[el.KeyId] = true
+	}
+
+	var remediated []keyStatus
+	for _, el := range statuses {
+		if !failedKeys[*el.Key.KeyMetadata.KeyId] {
+			remediated = append(remediated, el)
+		}
+	}
+	return remediated
+}
+
+func provenanceTags(cfg Config, now time.Time) []types.Tag {
+	/*
+	Function that builds the tags recording that a key was changed by this Lambda.
+
+	:param cfg: The run settings, used for the tag keys and values
+	:param now: The time of the remediation
+	:return: A slice of the tags to write on the key
+	*/
+
+	return []types.Tag{
+		{TagKey: aws.String(cfg.RemediatedByTag), TagValue: aws.String(cfg.RemediatedBy)},
+		{TagKey: aws.String(cfg.RemediatedAtTag), TagValue: aws.String(now.UTC().Format(time.RFC3339))},
+		{TagKey: aws.String(cfg.ControlIdTag), TagValue: aws.String(cfg.ControlId)},
+	}
+}
+
+func tagRemediated(client KMSActionsAPI, remediated []keyStatus, cfg Config, now time.Time) []KeyFailure {
+	/*
+	Function that tags the remediated CMK's with who changed them, when and for which control.
+
+	A key that cannot be tagged is returned as a failure, its remediation still counts.
+
+	:param client: An instantiated struct that contains methods matching the KMSActionsAPI interface
+	:param remediated: A slice of rotation status for the keys that were set to rotate
+	:param cfg: The run settings, used for the tag keys and values
+	:param now: The time of the remediation
+	:return: A slice of the keys that could not be tagged, with the stage and reason
+	*/
+
+	failures := []KeyFailure{}
+	tags := provenanceTags(cfg, now)
+
+	for _, el := range remediated {
+		params := &kms.TagResourceInput{
+			KeyId: el.Key.KeyMetadata.KeyId,
+			Tags:  tags,
+		}
+
+		err := withRetry(func() error {
+			_, err := client.TagResource(context.TODO(), params)
+			return err
+		})
+
+		if err != nil {
+			log.Println(err)
+			failures = append(failures, newKeyFailure(*el.Key.KeyMetadata.KeyId, stageTagResource, err))
+		}
+	}
+	return failures
+}
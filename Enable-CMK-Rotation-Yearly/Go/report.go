@@ -0,0 +1,212 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// actions that are reported for each key
+const (
+	actionNone           = "none"
+	actionEnableRotation = "enable-rotation"
+	actionUpdatePeriod   = "update-rotation-period"
+	actionExempt         = "exempt"
+)
+
+// report entry for a single customer managed key
+type KeyReport struct {
+	KeyId              string `json:"keyId"`
+	Arn                string `json:"arn"`
+	RotationEnabled    bool   `json:"rotationEnabled"`
+	RotationPeriodDays int32  `json:"rotationPeriodDays,omitempty"`
+	TargetPeriodDays   int32  `json:"targetPeriodDays"`
+	Exemption          string `json:"exemption,omitempty"`
+	Action             string `json:"action"`
+	MaterialAgeDays    int    `json:"materialAgeDays,omitempty"`
+	OnDemandRotations  int    `json:"onDemandRotations,omitempty"`
+	OnDemand           string `json:"onDemand,omitempty"`
+}
+
+// customer managed key that KMS cannot rotate automatically, with its eligibility class
+type IneligibleKey struct {
+	KeyId  string `json:"keyId"`
+	Arn    string `json:"arn"`
+	Reason string `json:"reason"`
+}
+
+// multi-Region replica key, rotation is set on and inherited from the primary key
+type ReplicaKey struct {
+	KeyId         string `json:"keyId"`
+	Arn           string `json:"arn"`
+	PrimaryArn    string `json:"primaryArn"`
+	PrimaryRegion string `json:"primaryRegion"`
+	Status        string `json:"status"`
+}
+
+// issue found in a key policy statement
+type PolicyFinding struct {
+	KeyId      string   `json:"keyId"`
+	PolicyName string   `json:"policyName"`
+	Sid        string   `json:"sid,omitempty"`
+	Issue      string   `json:"issue"`
+	Principals []string `json:"principals,omitempty"`
+	Actions    []string `json:"actions"`
+}
+
+// grant on a customer managed key, with the flags that apply to it
+type GrantReport struct {
+	KeyId            string   `json:"keyId"`
+	GrantId          string   `json:"grantId"`
+	Name             string   `json:"name,omitempty"`
+	GranteePrincipal string   `json:"granteePrincipal"`
+	Operations       []string `json:"operations"`
+	CreationDate     string   `json:"creationDate,omitempty"`
+	AgeDays          int      `json:"ageDays"`
+	Flags            []string `json:"flags"`
+}
+
+// grant removed because it matched the revocation rule
+type RevokedGrant struct {
+	KeyId            string   `json:"keyId"`
+	GrantId          string   `json:"grantId"`
+	GranteePrincipal string   `json:"granteePrincipal"`
+	Flags            []string `json:"flags"`
+	Method           string   `json:"method"`
+}
+
+// key with no alias, so no team can be found that owns it
+type UnownedKey struct {
+	KeyId string `json:"keyId"`
+	Arn   string `json:"arn,omitempty"`
+}
+
+// customer managed key scheduled for deletion
+type PendingDeletionKey struct {
+	KeyId        string `json:"keyId"`
+	Arn          string `json:"arn"`
+	DeletionDate string `json:"deletionDate,omitempty"`
+	Protected    bool   `json:"protected"`
+	Action       string `json:"action"`
+}
+
+// stages of the workflow a key can fail in
+const (
+	stageDescribeKey    = "describe-key"
+	stageListTags       = "list-resource-tags"
+	stageRotationStatus = "get-key-rotation-status"
+	stageEnableRotation = "enable-key-rotation"
+	stageListRotations  = "list-key-rotations"
+	stageRotateOnDemand = "rotate-key-on-demand"
+	stageGetKeyPolicy   = "get-key-policy"
+	stageParseKeyPolicy = "parse-key-policy"
+	stageListGrants     = "list-grants"
+	stageRevokeGrant    = "revoke-grant"
+	stageCancelDeletion = "cancel-key-deletion"
+	stageEnableKey      = "enable-key"
+	stageTagResource    = "tag-resource"
+)
+
+// key that could not be checked or remediated, with the stage and the error returned for it
+type KeyFailure struct {
+	KeyId  string `json:"keyId"`
+	Stage  string `json:"stage"`
+	Reason string `json:"reason"`
+}
+
+// result returned by the handler so callers such as Step Functions can branch on the outcome,
+// in audit mode the key actions are what would have been changed
+type Report struct {
+	Mode               string               `json:"mode"`
+	KeysScanned        int                  `json:"keysScanned"`
+	AwsManagedSkipped  int                  `json:"awsManagedSkipped"`
+	OutOfScope         int                  `json:"outOfScope"`
+	Compliant          int                  `json:"compliant"`
+	Exempt             int                  `json:"exempt"`
+	Remediated         int                  `json:"remediated"`
+	RotatedOnDemand    int                  `json:"rotatedOnDemand"`
+	DeletionsCancelled int                  `json:"deletionsCancelled"`
+	Failed             []KeyFailure         `json:"failed"`
+	TagFailures        []KeyFailure         `json:"tagFailures"`
+	DurationMs         int64                `json:"durationMs"`
+	Error              string               `json:"error,omitempty"`
+	Keys               []KeyReport          `json:"keys"`
+	Ineligible         []IneligibleKey      `json:"ineligible"`
+	Replicas           []ReplicaKey         `json:"replicas"`
+	PolicyFindings     []PolicyFinding      `json:"policyFindings"`
+	Grants             []GrantReport        `json:"grants"`
+	RevokedGrants      []RevokedGrant       `json:"revokedGrants"`
+	Unowned            []UnownedKey         `json:"unowned"`
+	PendingDeletion    []PendingDeletionKey `json:"pendingDeletion"`
+}
+
+func newReport(mode string) Report {
+	/*
+	Function that creates an empty report, the slices are set so they encode as [] and not null.
+
+	:param mode: The run mode, either 'audit' or 'remediate'
+	:return: A report with zero totals
+	*/
+
+	return Report{
+		Mode:            mode,
+		Failed:          []KeyFailure{},
+		Keys:            []KeyReport{},
+		Ineligible:      []IneligibleKey{},
+		Replicas:        []ReplicaKey{},
+		PolicyFindings:  []PolicyFinding{},
+		Grants:          []GrantReport{},
+		RevokedGrants:   []RevokedGrant{},
+		Unowned:         []UnownedKey{},
+		PendingDeletion: []PendingDeletionKey{},
+		TagFailures:     []KeyFailure{},
+	}
+}
+
+func buildKeyReports(statuses []keyStatus) []KeyReport {
+	/*
+	Function that builds the report entries for the CMK's.
+
+	:param statuses: A slice containing the rotation status for every CMK.
+	:return: A slice with an entry for every CMK
+	*/
+
+	entries := []KeyReport{}
+
+	for _, el := range statuses {
+		entry := KeyReport{
+			KeyId:              *el.Key.KeyMetadata.KeyId,
+			Arn:                aws.ToString(el.Key.KeyMetadata.Arn),
+			RotationEnabled:    el.RotationEnabled,
+			RotationPeriodDays: el.PeriodDays,
+			TargetPeriodDays:   el.TargetDays,
+			Exemption:          el.Exemption.String(),
+			Action:             actionNone,
+			MaterialAgeDays:    el.Material.AgeDays,
+			OnDemandRotations:  el.Material.OnDemandRotations,
+			OnDemand:           el.OnDemand,
+		}
+
+		if el.Exemption.Exempt {
+			entry.Action = actionExempt
+		} else if !el.RotationEnabled {
+			entry.Action = actionEnableRotation
+		} else if !el.Compliant {
+			entry.Action = actionUpdatePeriod
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func newKeyFailure(keyId string, stage string, err error) KeyFailure {
+	/*
+	Function that builds the failure entry for a key.
+
+	:param keyId: The ID of the key that failed
+	:param stage: The stage of the workflow the key failed in
+	:param err: The error returned by AWS
+	:return: The failure entry for the report
+	*/
+
+	return KeyFailure{KeyId: keyId, Stage: stage, Reason: err.Error()}
+}
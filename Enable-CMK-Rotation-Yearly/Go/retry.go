@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+// retry settings for throttled KMS calls, the SDK client is set to a single
+// attempt so these are the only retries made
+const (
+	maxAttempts = 5
+	baseDelay   = 200 * time.Millisecond
+	maxDelay    = 5 * time.Second
+)
+
+// error codes KMS returns when a request is throttled
+var throttleCodes = map[string]bool{
+	"ThrottlingException":      true,
+	"Throttling":               true,
+	"TooManyRequestsException": true,
+	"RequestLimitExceeded":     true,
+}
+
+// sleep is replaced in tests so retries do not slow them down
+var sleep = time.Sleep
+
+func isThrottle(err error) bool {
+	/*
+	Function that checks whether an error from AWS is a throttling error.
+
+	:param err: The error returned by an API call
+	:return: A bool that is true when the call was throttled
+	*/
+
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && throttleCodes[apiErr.ErrorCode()]
+}
+
+func withRetry(call func() error) error {
+	/*
+	Function that runs an API call and retries it with jittered exponential backoff when throttled.
+
+	Any other error is returned straight away, and so is the error of the last
+	attempt, without waiting for another backoff.
+
+	:param call: The function making the API call
+	:return: The error of the last attempt, or nil on success
+	*/
+
+	var err error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		err = call()
+		if err == nil || !isThrottle(err) || attempt == maxAttempts-1 {
+			return err
+		}
+
+		// full jitter, a random delay up to the exponential backoff cap
+		backoff := baseDelay << attempt
+		if backoff > maxDelay {
+			backoff = maxDelay
+		}
+		delay := time.Duration(rand.Int63n(int64(backoff)))
+
+		log.Printf("[!] Throttled, retrying in %v (attempt %d of %d).\n", delay, attempt+1, maxAttempts)
+		sleep(delay)
+	}
+	return err
+}
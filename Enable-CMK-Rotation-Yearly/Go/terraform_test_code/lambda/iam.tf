@@ -31,7 +31,10 @@ resource "aws_iam_policy" "lambda_policy_for_kms_rotation" {
         {
             "Sid": "VisualEditor0",
             "Effect": "Allow",
-            "Action": "kms:ListKeys",
+            "Action": [
+                "kms:ListKeys",
+                "organizations:ListAccounts"
+            ],
             "Resource": "*"
         },
         {
@@ -41,6 +44,18 @@ resource "aws_iam_policy" "lambda_policy_for_kms_rotation" {
                 "kms:EnableKeyRotation",
                 "kms:GetKeyRotationStatus",
                 "kms:DescribeKey",
+                "kms:ListResourceTags",
+                "kms:ListKeyRotations",
+                "kms:RotateKeyOnDemand",
+                "kms:ListKeyPolicies",
+                "kms:GetKeyPolicy",
+                "kms:ListGrants",
+                "kms:RevokeGrant",
+                "kms:RetireGrant",
+                "kms:ListAliases",
+                "kms:CancelKeyDeletion",
+                "kms:EnableKey",
+                "kms:TagResource",
                 "logs:PutLogEvents"
             ],
             "Resource": [
@@ -14,3 +14,29 @@ resource "aws_lambda_function" "versioning_lambda" {
   runtime = "go1.x"
 
 }
+
+resource "aws_cloudwatch_event_rule" "kms_key_events" {
+  name = "${var.lambda_func_name}-key-events"
+  event_pattern = <<EOF
+{
+  "source": ["aws.kms"],
+  "detail-type": ["AWS API Call via CloudTrail"],
+  "detail": {
+    "eventName": ["CreateKey", "DisableKeyRotation", "ImportKeyMaterial", "ScheduleKeyDeletion"]
+  }
+}
+EOF
+}
+
+resource "aws_cloudwatch_event_target" "kms_key_events" {
+  rule = aws_cloudwatch_event_rule.kms_key_events.name
+  arn = aws_lambda_function.versioning_lambda.arn
+}
+
+resource "aws_lambda_permission" "kms_key_events" {
+  statement_id = "AllowExecutionFromEventBridge"
+  action = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.versioning_lambda.function_name
+  principal = "events.amazonaws.com"
+  source_arn = aws_cloudwatch_event_rule.kms_key_events.arn
+}
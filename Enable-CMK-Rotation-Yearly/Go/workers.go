@@ -0,0 +1,41 @@
+package main
+
+import (
+	"sync"
+)
+
+func runWorkers(count int, concurrency int, fn func(i int)) {
+	/*
+	Function that calls fn for every index from 0 to count over a bounded pool of goroutines.
+
+	Callers store results by index, so the output order does not depend on scheduling.
+
+	:param count: The number of items to process
+	:param concurrency: The maximum number of goroutines running fn at once
+	:param fn: The function called with the index of each item
+	:return: None, returns once every item has been processed
+	*/
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency && w < count; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -3,9 +3,8 @@ package main
 import (
 	"context"
 	"log"
-	"strconv"
-	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
@@ -16,37 +15,66 @@ type Bucket struct {
 	Struct that contains the attrs and methods to set S3 versioning on buckets.
 	*/
 	Client S3ActionsApi
+	Regional regionalClients
 	BucketList []string
+	BucketRegions map[string]string
+	Config Config
+	Suspensions map[string]Suspension
+	limiter *regionLimiter
+	regionErrors map[string]error
 }
 
-func (b *Bucket) bucketList() {
+func (b *Bucket) bucketList() error {
 	/*
 	Private method that checks what buckets are available to the role in the AWS account.
 
-	:return: nil
-	*/	
-	resp, err := b.Client.ListBuckets(context.TODO(), nil)
+	Every page of 'ListBuckets' is read, and the region of each bucket is kept
+	so it can be called through the client for its region. A bucket already in
+	the struct slice is not added again, so it is only checked once. When a page
+	fails, the buckets listed so far are kept so they can still be checked.
 
-	if err != nil {
-		log.Println(err)
+	:return: nil when every page was read, or the error from AWS that stopped the listing
+	*/
+	if b.BucketRegions == nil {
+		b.BucketRegions = make(map[string]string)
 	}
 
-	for _, bucket := range resp.Buckets {
-		if len(*bucket.Name) != 0 {
-			b.BucketList = append(b.BucketList, *bucket.Name)
+	listed := make(map[string]bool)
+	for _, bucket := range b.BucketList {
+		listed[bucket] = true
+	}
+
+	paginator := s3.NewListBucketsPaginator(b.Client, &s3.ListBucketsInput{})
+
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+
+		for _, bucket := range resp.Buckets {
+			if len(aws.ToString(bucket.Name)) != 0 && !listed[*bucket.Name] {
+				listed[*bucket.Name] = true
+				b.BucketList = append(b.BucketList, *bucket.Name)
+			}
+
+			if region := aws.ToString(bucket.BucketRegion); region != "" {
+				b.BucketRegions[aws.ToString(bucket.Name)] = region
+			}
 		}
 	}
 
+	return nil
 }
 
-func (b *Bucket) updateBucketVersion(bucket string) bool {
+func (b *Bucket) updateBucketVersion(bucket string) error {
 	/*
 	Private method to set versioning on a specific S3 bucket within the AWS account.
 
-	Will return 'true' if the method runs successfully.
-
 	:param bucket: (required) A string containing the S3 bucket name
-	:return: A boolean result when run successfully
+	:return: nil when run successfully, or an error from AWS
 	*/
 	params := &s3.PutBucketVersioningInput {
 		Bucket: &bucket,
@@ -55,13 +83,13 @@ func (b *Bucket) updateBucketVersion(bucket string) bool {
 		},
 	}
 
-	_, err := b.Client.PutBucketVersioning(context.TODO(), params)
+	_, err := b.clientFor(bucket).PutBucketVersioning(context.TODO(), params)
 
 	if err != nil {
 		log.Println(err)
 	}
 
-	return true
+	return err
 }
 
 func (b *Bucket) getBucketVersion(bucket string) (*s3.GetBucketVersioningOutput, error) {
@@ -75,81 +103,230 @@ func (b *Bucket) getBucketVersion(bucket string) (*s3.GetBucketVersioningOutput,
 	params := &s3.GetBucketVersioningInput {
 		Bucket: &bucket,
 	}
-	return b.Client.GetBucketVersioning(context.TODO(), params)
+	return b.clientFor(bucket).GetBucketVersioning(context.TODO(), params)
 }
 
-func (b *Bucket) checkBucketVersion() map[string]string{
+func (b *Bucket) checkBucketVersion() []BucketStatus {
 	/*
-	Private method that will make a map key with the bucket version status.
+	Private method that will get the versioning status of every bucket in the struct slice.
 
-	The map key will be concatenated with the index value of the struct slice.
+	The calls are spread over a pool of Config.Concurrency workers, and results are
+	stored by index so the order does not depend on scheduling. A bucket that cannot
+	be checked, or whose region could not be found, is returned with the error and
+	no action. The tags are read first,
+	so the owner tags and the reason a bucket is excluded are recorded. A bucket
+	whose tags cannot be read is still checked, and the tag error is recorded. The MFA Delete
+	and Object Lock posture is reported for every bucket.
 
-	:return: A map with a string key and string value (i.e., {"enabled0": "bucketName", "suspended1": "bucketnane1"})
+	:return: A slice of status records in the order of the struct slice
 	*/
-	m := make(map[string]string)
-	for i, bucket := range b.BucketList {
-		resp, err := b.getBucketVersion(bucket)
+	statuses := make([]BucketStatus, len(b.BucketList))
+
+	runWorkers(len(b.BucketList), b.Config.Concurrency, func(i int) {
+		bucket := b.BucketList[i]
+		statuses[i] = newBucketStatus(bucket, b.BucketRegions[bucket])
 
+		// the default client would be redirected for a bucket in another region
+		if err := b.regionErrors[bucket]; err != nil {
+			statuses[i].Error = "unable to find bucket region: " + err.Error()
+			return
+		}
+
+		var tags map[string]string
+		var err error
+		b.inRegion(bucket, func() {
+			tags, err = b.getBucketTags(bucket)
+		})
+
+		// a bucket whose tags cannot be read is still checked, with only the name based exclusions
 		if err != nil {
 			log.Println(err)
+			statuses[i].TagError = err.Error()
+		} else {
+			statuses[i].tags = tags
+			statuses[i].OwnerTags = ownerTags(tags, b.Config.OwnerTags)
 		}
-			// need to perform a conversion on the index number to avoid a testing error
-			if resp.Status == "Suspended" {
-				m["suspended" + strconv.FormatInt(int64(i), 10)] = bucket
-			} else if resp.Status == "Enabled"{
-				m["enabled" + strconv.FormatInt(int64(i), 10)] = bucket
-			} else {
-				m["disabled" + strconv.FormatInt(int64(i), 10)] = bucket
-			}
-	}
-	return m
-}
 
-func (b *Bucket) removeItemFromDict(mapKey string, bucketMap map[string]string) bool {
-	/*
-	Private method that will remove items from the shallow copy of the map.
+		statuses[i].Excluded = exclusionReason(bucket, statuses[i].tags, b.Config)
 
-	:param mapKey: (required) The string key that will point to the val in the map to remove
-	:param bucketMap: (required) A map with a string key and a string value for bucket name and status
-	:return: A boolean result that depends on whether the map deletion was successful
-	*/
-	if _, ok := bucketMap[mapKey]; ok {
-		delete(bucketMap, mapKey)
-		return true
-	}
-	return false
+		var resp *s3.GetBucketVersioningOutput
+		b.inRegion(bucket, func() {
+			resp, err = b.getBucketVersion(bucket)
+		})
+
+		if err != nil {
+			log.Println(err)
+			statuses[i].Error = err.Error()
+			return
+		}
+
+		if resp.Status != "" {
+			statuses[i].Versioning = string(resp.Status)
+		}
+
+		if resp.MFADelete != "" {
+			statuses[i].MFADelete = string(resp.MFADelete)
+		}
+
+		// the Object Lock posture is report only, so an error does not stop the bucket being remediated
+		b.inRegion(bucket, func() {
+			statuses[i].ObjectLock, err = b.getObjectLock(bucket)
+		})
+
+		if err != nil {
+			log.Println(err)
+			statuses[i].ObjectLock.Error = err.Error()
+		}
+
+		statuses[i].Immutable = immutableFindings(statuses[i], b.Config)
+		if len(statuses[i].Immutable) > 0 {
+			log.Printf("ALERT: immutable bucket %v lacks %v\n", bucket, statuses[i].Immutable)
+		}
+	})
+	return statuses
 }
 
-func (b *Bucket) Dispatch() {
+func (b *Bucket) Dispatch() ([]BucketStatus, error) {
 	/*
 	Public method that will call all the private methods in the correct order.
 
-	Will check that key contains either "suspended" or "disabled" to perform the bucket versioning.
+	Will enable versioning on every bucket that is "Suspended" or "Disabled", or only
+	alert on it, following the policy for its state. Buckets that opt in also get a
+	lifecycle rule expiring noncurrent versions. Suspended buckets also get a
+	finding, with who suspended versioning when it is known from an event. Both
+	phases run over a pool of Config.Concurrency workers, with at most
+	Config.RegionConcurrency calls in flight per region. When listing the buckets
+	fails part way, the buckets listed so far are still checked.
 
-	:return: nil
+	:return: A slice with a status record for every bucket, with the action taken, and the error that stopped the listing
 	*/
-	b.bucketList()
+	b.limiter = newRegionLimiter(b.Config.RegionConcurrency)
+	listErr := b.bucketList()
+	b.lookupRegions()
 
 	if len(b.BucketList) == 0 {
 		log.Println("No buckets found in account.")
 	}
 
-	bucketMap := b.checkBucketVersion()
+	regions, groups := b.bucketsByRegion()
+	for _, region := range regions {
+		log.Printf("Found %d buckets in region %v\n", len(groups[region]), region)
+	}
+
+	return b.remediate(), listErr
+}
 
-	for status, bucket := range bucketMap {
-		copyMap := bucketMap
+func (b *Bucket) DispatchBucket(bucket string, region string) []BucketStatus {
+	/*
+	Public method that will check and remediate the single bucket named in a CloudTrail event.
+
+	No 'ListBuckets' sweep is made, so a new or suspended bucket has versioning
+	enabled within seconds of the event. The same policies as 'Dispatch' apply.
 
-		if strings.Contains(status, "suspended") || strings.Contains(status, "disabled") {
-			if b.removeItemFromDict(status, copyMap) {
-				b.updateBucketVersion(bucket)
-				log.Printf("Enabled versioning on bucket %v\n", bucket)
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:param region: The region from the event, the bucket location is looked up when it is empty
+	:return: A slice with the status record of the bucket, with the action taken
+	*/
+	b.limiter = newRegionLimiter(b.Config.RegionConcurrency)
+	b.BucketList = []string{bucket}
+	b.BucketRegions = map[string]string{bucket: region}
+	b.lookupRegions()
+
+	log.Printf("Checking bucket %v from event\n", bucket)
+	return b.remediate()
+}
+
+func (b *Bucket) remediate() []BucketStatus {
+	/*
+	Private method that checks the buckets in the struct slice and applies the policy for their state.
 
-				bucketMap = copyMap
+	When turned on, Block Public Access and default encryption are then enforced
+	on the same buckets.
+
+	:return: A slice with a status record for every bucket, with the action taken
+	*/
+	statuses := b.checkBucketVersion()
+	var pending []int
+
+	for i, status := range statuses {
+		if status.Error != "" {
+			continue
+		}
+
+		if status.Excluded != "" {
+			log.Printf("Bucket %v is excluded (%v)\n", status.Name, status.Excluded)
+			continue
+		}
+
+		if status.Versioning == statusEnabled {
+			log.Printf("Bucket %v already had versioning\n", status.Name)
+			continue
+		}
+
+		// suspension is a deliberate change, so it is reported whatever the policy
+		if status.Versioning == statusSuspended {
+			suspension, ok := b.Suspensions[status.Name]
+			if !ok {
+				suspension = Suspension{Finding: findingSuspended}
 			}
+			statuses[i].Suspension = &suspension
+		}
 
-		} else {
-			log.Printf("Bucket %v already had versioning\n", bucket)
+		switch b.Config.policyFor(status.Versioning) {
+		case policyIgnore:
+			continue
+		case policyAlertOnly:
+			statuses[i].Action = actionAlert
+			log.Printf("ALERT: bucket %v has versioning %v\n", status.Name, status.Versioning)
+			continue
+		}
+
+		statuses[i].Action = actionEnableVersioning
+		pending = append(pending, i)
+	}
+
+	// each worker only writes the record at its own index
+	runWorkers(len(pending), b.Config.Concurrency, func(n int) {
+		status := &statuses[pending[n]]
+
+		var err error
+		b.inRegion(status.Name, func() {
+			err = b.updateBucketVersion(status.Name)
+		})
+
+		if err != nil {
+			status.Error = err.Error()
+			return
+		}
+
+		log.Printf("Enabled versioning on bucket %v\n", status.Name)
+
+		// keep the noncurrent versions from growing storage without limit
+		if !wantsLifecycle(status.tags, b.Config) {
+			return
+		}
+
+		var lifecycle string
+		b.inRegion(status.Name, func() {
+			lifecycle, err = b.ensureLifecycle(status.Name)
+		})
+
+		if err != nil {
+			log.Println(err)
+			status.Error = "versioning enabled, lifecycle rule not added: " + err.Error()
+			return
 		}
+		status.Lifecycle = lifecycle
+	})
+
+	// bucket level Block Public Access, in case the account level settings are relaxed
+	if b.Config.PublicAccessBlock {
+		b.enforcePublicAccessBlock(statuses)
 	}
 
-}
\ No newline at end of file
+	if b.Config.EncryptionControl {
+		b.enforceEncryption(statuses)
+	}
+
+	return statuses
+}
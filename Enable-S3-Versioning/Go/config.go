@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaults used when the matching environment variable is not set
+const (
+	defaultConcurrency       = 10
+	defaultRegionConcurrency = 5
+	defaultExemptTag         = "security:versioning-exempt"
+	defaultOwnerTags         = "owner,team"
+	defaultLifecycleTag      = "security:noncurrent-expiry"
+	defaultNoncurrentDays    = 90
+	defaultImmutableTag      = "security:immutable"
+	defaultPublicAccessTag   = "security:public-access-exempt"
+)
+
+// what is done with a bucket that does not have versioning enabled
+const (
+	policyRemediate = "remediate"
+	policyAlertOnly = "alert-only"
+	policyIgnore    = "ignore"
+)
+
+// run settings of the Lambda, read from its environment variables
+type Config struct {
+	Concurrency           int
+	RegionConcurrency     int
+	SuspendedPolicy       string
+	DisabledPolicy        string
+	ExemptTag             string
+	ExcludePattern        *regexp.Regexp
+	Allowlist             map[string]bool
+	OwnerTags             []string
+	NoncurrentLifecycle   bool
+	LifecycleTag          string
+	NoncurrentDays        int32
+	ImmutableTag          string
+	PublicAccessBlock     bool
+	PublicAccessExemptTag string
+	EncryptionControl     bool
+	RequireSSEKMS         bool
+	ApprovedKMSKeys       map[string]bool
+	EncryptionRemediation bool
+	EncryptionKeyArn      string
+}
+
+func loadConfig() (Config, error) {
+	/*
+	Function that reads the run settings from the Lambda environment variables.
+
+	:return: A Config struct with defaults applied, or an error for an invalid value
+	*/
+
+	cfg := Config{
+		Concurrency:           defaultConcurrency,
+		RegionConcurrency:     defaultRegionConcurrency,
+		SuspendedPolicy:       policyRemediate,
+		DisabledPolicy:        policyRemediate,
+		ExemptTag:             defaultExemptTag,
+		Allowlist:             map[string]bool{},
+		OwnerTags:             splitList(defaultOwnerTags),
+		LifecycleTag:          defaultLifecycleTag,
+		NoncurrentDays:        defaultNoncurrentDays,
+		ImmutableTag:          defaultImmutableTag,
+		PublicAccessExemptTag: defaultPublicAccessTag,
+		ApprovedKMSKeys:       map[string]bool{},
+	}
+
+	if val := os.Getenv("WORKER_CONCURRENCY"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("WORKER_CONCURRENCY must be a positive integer, got %q", val)
+		}
+		cfg.Concurrency = n
+	}
+
+	if val := os.Getenv("REGION_CONCURRENCY"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("REGION_CONCURRENCY must be a positive integer, got %q", val)
+		}
+		cfg.RegionConcurrency = n
+	}
+
+	if val := os.Getenv("SUSPENDED_POLICY"); val != "" {
+		if !validPolicy(val) {
+			return cfg, fmt.Errorf("SUSPENDED_POLICY must be %q, %q or %q, got %q", policyRemediate, policyAlertOnly, policyIgnore, val)
+		}
+		cfg.SuspendedPolicy = val
+	}
+
+	if val := os.Getenv("DISABLED_POLICY"); val != "" {
+		if !validPolicy(val) {
+			return cfg, fmt.Errorf("DISABLED_POLICY must be %q, %q or %q, got %q", policyRemediate, policyAlertOnly, policyIgnore, val)
+		}
+		cfg.DisabledPolicy = val
+	}
+
+	if val := os.Getenv("EXEMPT_TAG"); val != "" {
+		cfg.ExemptTag = val
+	}
+
+	if val := os.Getenv("EXCLUDE_NAME_PATTERN"); val != "" {
+		pattern, err := regexp.Compile(val)
+		if err != nil {
+			return cfg, fmt.Errorf("EXCLUDE_NAME_PATTERN must be a valid regular expression, %v", err)
+		}
+		cfg.ExcludePattern = pattern
+	}
+
+	for _, bucket := range splitList(os.Getenv("EXCLUDE_BUCKETS")) {
+		cfg.Allowlist[bucket] = true
+	}
+
+	if val := os.Getenv("OWNER_TAGS"); val != "" {
+		cfg.OwnerTags = splitList(val)
+	}
+
+	if val := os.Getenv("NONCURRENT_LIFECYCLE"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("NONCURRENT_LIFECYCLE must be true or false, got %q", val)
+		}
+		cfg.NoncurrentLifecycle = enabled
+	}
+
+	if val := os.Getenv("LIFECYCLE_TAG"); val != "" {
+		cfg.LifecycleTag = val
+	}
+
+	if val := os.Getenv("NONCURRENT_EXPIRATION_DAYS"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("NONCURRENT_EXPIRATION_DAYS must be a positive integer, got %q", val)
+		}
+		cfg.NoncurrentDays = int32(n)
+	}
+
+	if val := os.Getenv("IMMUTABLE_TAG"); val != "" {
+		cfg.ImmutableTag = val
+	}
+
+	if val := os.Getenv("PUBLIC_ACCESS_BLOCK"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("PUBLIC_ACCESS_BLOCK must be true or false, got %q", val)
+		}
+		cfg.PublicAccessBlock = enabled
+	}
+
+	if val := os.Getenv("PUBLIC_ACCESS_EXEMPT_TAG"); val != "" {
+		cfg.PublicAccessExemptTag = val
+	}
+
+	if val := os.Getenv("ENCRYPTION_CONTROL"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("ENCRYPTION_CONTROL must be true or false, got %q", val)
+		}
+		cfg.EncryptionControl = enabled
+	}
+
+	if val := os.Getenv("REQUIRE_SSE_KMS"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("REQUIRE_SSE_KMS must be true or false, got %q", val)
+		}
+		cfg.RequireSSEKMS = enabled
+	}
+
+	for _, key := range splitList(os.Getenv("APPROVED_KMS_KEYS")) {
+		cfg.ApprovedKMSKeys[key] = true
+	}
+
+	if val := os.Getenv("ENCRYPTION_REMEDIATION"); val != "" {
+		enabled, err := strconv.ParseBool(val)
+		if err != nil {
+			return cfg, fmt.Errorf("ENCRYPTION_REMEDIATION must be true or false, got %q", val)
+		}
+		cfg.EncryptionRemediation = enabled
+	}
+
+	cfg.EncryptionKeyArn = os.Getenv("ENCRYPTION_KMS_KEY_ARN")
+
+	// remediation must apply a key that passes the same checks
+	if cfg.EncryptionRemediation {
+		if !strings.HasPrefix(cfg.EncryptionKeyArn, "arn:") {
+			return cfg, fmt.Errorf("ENCRYPTION_KMS_KEY_ARN must be a KMS key ARN when ENCRYPTION_REMEDIATION is on, got %q", cfg.EncryptionKeyArn)
+		}
+
+		if len(cfg.ApprovedKMSKeys) > 0 && !approvedKey(cfg.EncryptionKeyArn, cfg.ApprovedKMSKeys) {
+			return cfg, fmt.Errorf("ENCRYPTION_KMS_KEY_ARN %q is not in APPROVED_KMS_KEYS", cfg.EncryptionKeyArn)
+		}
+	}
+
+	return cfg, nil
+}
+
+func validPolicy(val string) bool {
+	/*
+	Function that checks a versioning policy is one of the supported values.
+
+	:param val: The policy from the environment variable
+	:return: A bool that is true for 'remediate', 'alert-only' and 'ignore'
+	*/
+
+	return val == policyRemediate || val == policyAlertOnly || val == policyIgnore
+}
+
+func (c Config) policyFor(versioning string) string {
+	/*
+	Method that returns the policy for a versioning state.
+
+	An unset policy remediates, so a Bucket built without a Config keeps enabling versioning.
+
+	:param versioning: The versioning state, either 'Suspended' or 'Disabled'
+	:return: The policy for the state
+	*/
+
+	policy := c.DisabledPolicy
+	if versioning == statusSuspended {
+		policy = c.SuspendedPolicy
+	}
+
+	if policy == "" {
+		return policyRemediate
+	}
+	return policy
+}
+
+func splitList(val string) []string {
+	/*
+	Function that splits a comma separated environment variable into its values.
+
+	:param val: The comma separated string
+	:return: A slice of the trimmed, non-empty values
+	*/
+
+	var items []string
+	for _, el := range strings.Split(val, ",") {
+		if el = strings.TrimSpace(el); el != "" {
+			items = append(items, el)
+		}
+	}
+	return items
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// findings for the default encryption of a bucket
+const (
+	findingKMSRequired   = "sse-kms-required"
+	findingUnapprovedKey = "kms-key-not-approved"
+)
+
+// actions taken on the default encryption of a bucket
+const (
+	encryptionNone  = "none"
+	encryptionApply = "apply-sse-kms"
+)
+
+// default encryption of a bucket, the findings against the policy and the action taken
+type EncryptionStatus struct {
+	Algorithm        string   `json:"algorithm"`
+	KmsKeyId         string   `json:"kmsKeyId,omitempty"`
+	BucketKeyEnabled bool     `json:"bucketKeyEnabled"`
+	Findings         []string `json:"findings"`
+	Action           string   `json:"action"`
+	Error            string   `json:"error,omitempty"`
+
+	// rule read from the bucket, so settings this Lambda does not manage are kept
+	rule *types.ServerSideEncryptionRule
+}
+
+func isKMS(algorithm string) bool {
+	/*
+	Function that checks whether an encryption algorithm uses a KMS key.
+
+	:param algorithm: The SSE algorithm of the bucket
+	:return: A bool that is true for 'aws:kms' and 'aws:kms:dsse'
+	*/
+
+	return algorithm == string(types.ServerSideEncryptionAwsKms) || algorithm == string(types.ServerSideEncryptionAwsKmsDsse)
+}
+
+func approvedKey(keyId string, approved map[string]bool) bool {
+	/*
+	Function that checks whether a KMS key is on the approved list.
+
+	A bucket can name its key by ID or ARN, so the key ID at the end of an ARN is
+	also compared with the list.
+
+	:param keyId: The KMS key of the bucket
+	:param approved: The approved key IDs and ARNs
+	:return: A bool that is true when the key is approved
+	*/
+
+	if approved[keyId] {
+		return true
+	}
+
+	for key := range approved {
+		if i := strings.LastIndex(key, "/"); i >= 0 && key[i+1:] == keyId {
+			return true
+		}
+
+		if i := strings.LastIndex(keyId, "/"); i >= 0 && keyId[i+1:] == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bucket) getEncryption(bucket string) (EncryptionStatus, error) {
+	/*
+	Private method that reads the default encryption of an S3 bucket.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:return: The algorithm, KMS key and bucket key setting, 'none' when the bucket has no default encryption, or an error from AWS
+	*/
+
+	params := &s3.GetBucketEncryptionInput {
+		Bucket: aws.String(bucket),
+	}
+
+	status := EncryptionStatus{Algorithm: "none", Findings: []string{}, Action: encryptionNone}
+	resp, err := b.clientFor(bucket).GetBucketEncryption(context.TODO(), params)
+
+	// S3 returns an error for a bucket created before default encryption was applied to all buckets
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ServerSideEncryptionConfigurationNotFoundError" {
+		return status, nil
+	}
+
+	if err != nil {
+		return status, err
+	}
+
+	config := resp.ServerSideEncryptionConfiguration
+	if config == nil || len(config.Rules) == 0 {
+		return status, nil
+	}
+
+	rule := config.Rules[0]
+	status.rule = &rule
+	status.BucketKeyEnabled = aws.ToBool(rule.BucketKeyEnabled)
+
+	if rule.ApplyServerSideEncryptionByDefault != nil {
+		status.Algorithm = string(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+		status.KmsKeyId = aws.ToString(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID)
+	}
+	return status, nil
+}
+
+func encryptionFindings(status EncryptionStatus, cfg Config) []string {
+	/*
+	Function that checks the default encryption of a bucket against the encryption policy.
+
+	:param status: The default encryption of the bucket
+	:param cfg: The run settings, used for the SSE-KMS requirement and the approved keys
+	:return: A slice of the findings, empty when the bucket meets the policy
+	*/
+
+	findings := []string{}
+	if cfg.RequireSSEKMS && !isKMS(status.Algorithm) {
+		findings = append(findings, findingKMSRequired)
+	}
+
+	// a KMS bucket without a key uses the AWS managed key, which is never on the list
+	if isKMS(status.Algorithm) && len(cfg.ApprovedKMSKeys) > 0 && !approvedKey(status.KmsKeyId, cfg.ApprovedKMSKeys) {
+		findings = append(findings, findingUnapprovedKey)
+	}
+	return findings
+}
+
+func (b *Bucket) applyEncryption(bucket string, existing *types.ServerSideEncryptionRule) (types.ServerSideEncryption, error) {
+	/*
+	Private method that sets SSE-KMS with the configured key and bucket keys as the default encryption of a bucket.
+
+	A bucket already on a KMS algorithm keeps it, so a bucket on DSSE-KMS only has
+	its key changed and is not moved down to SSE-KMS.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:param existing: The current encryption rule of the bucket, its KMS algorithm and blocked encryption types are kept
+	:return: The algorithm that was set, or an error from AWS
+	*/
+
+	algorithm := types.ServerSideEncryptionAwsKms
+	if existing != nil && existing.ApplyServerSideEncryptionByDefault != nil && isKMS(string(existing.ApplyServerSideEncryptionByDefault.SSEAlgorithm)) {
+		algorithm = existing.ApplyServerSideEncryptionByDefault.SSEAlgorithm
+	}
+
+	rule := types.ServerSideEncryptionRule {
+		ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault {
+			SSEAlgorithm: algorithm,
+			KMSMasterKeyID: aws.String(b.Config.EncryptionKeyArn),
+		},
+		BucketKeyEnabled: aws.Bool(true),
+	}
+
+	if existing != nil {
+		rule.BlockedEncryptionTypes = existing.BlockedEncryptionTypes
+	}
+
+	params := &s3.PutBucketEncryptionInput {
+		Bucket: aws.String(bucket),
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration {
+			Rules: []types.ServerSideEncryptionRule{rule},
+		},
+	}
+
+	_, err := b.clientFor(bucket).PutBucketEncryption(context.TODO(), params)
+	return algorithm, err
+}
+
+func (b *Bucket) enforceEncryption(statuses []BucketStatus) {
+	/*
+	Private method that checks the default encryption of the buckets against the encryption policy.
+
+	Buckets with findings are only changed when Config.EncryptionRemediation is
+	set, and their record then shows the encryption that was applied, with the
+	findings that caused it. Buckets whose region could not be found are skipped,
+	as the default client would be redirected. The calls are spread over a pool
+	of Config.Concurrency workers and each record is updated in place.
+
+	:param statuses: The status records of the buckets in the struct slice
+	:return: nil
+	*/
+	runWorkers(len(statuses), b.Config.Concurrency, func(i int) {
+		status := &statuses[i]
+		if b.regionErrors[status.Name] != nil {
+			return
+		}
+
+		var encryption EncryptionStatus
+		var err error
+		b.inRegion(status.Name, func() {
+			encryption, err = b.getEncryption(status.Name)
+		})
+		status.Encryption = &encryption
+
+		if err != nil {
+			log.Println(err)
+			encryption.Error = err.Error()
+			return
+		}
+
+		encryption.Findings = encryptionFindings(encryption, b.Config)
+		if len(encryption.Findings) == 0 {
+			return
+		}
+
+		log.Printf("ALERT: bucket %v default encryption %v\n", status.Name, encryption.Findings)
+		if !b.Config.EncryptionRemediation {
+			return
+		}
+
+		encryption.Action = encryptionApply
+		var algorithm types.ServerSideEncryption
+		b.inRegion(status.Name, func() {
+			algorithm, err = b.applyEncryption(status.Name, encryption.rule)
+		})
+
+		if err != nil {
+			log.Println(err)
+			encryption.Error = err.Error()
+			return
+		}
+
+		encryption.Algorithm = string(algorithm)
+		encryption.KmsKeyId = b.Config.EncryptionKeyArn
+		encryption.BucketKeyEnabled = true
+		log.Printf("Applied %v default encryption on bucket %v\n", algorithm, status.Name)
+	})
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// EventBridge source and detail type of CloudTrail API call events
+const (
+	sourceS3          = "aws.s3"
+	detailTypeAPICall = "AWS API Call via CloudTrail"
+)
+
+// payload the Lambda is invoked with, scheduled events leave the CloudTrail fields empty
+type VersioningEvent struct {
+	Source     string          `json:"source"`
+	DetailType string          `json:"detail-type"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// the parts of a CloudTrail S3 event needed to find the bucket and who acted on it
+type cloudTrailDetail struct {
+	EventName    string `json:"eventName"`
+	EventTime    string `json:"eventTime"`
+	AwsRegion    string `json:"awsRegion"`
+	ErrorCode    string `json:"errorCode"`
+	UserIdentity struct {
+		Arn string `json:"arn"`
+	} `json:"userIdentity"`
+	RequestParameters struct {
+		BucketName              string `json:"bucketName"`
+		VersioningConfiguration struct {
+			Status string `json:"Status"`
+		} `json:"VersioningConfiguration"`
+	} `json:"requestParameters"`
+}
+
+func readEvent(event VersioningEvent) (*cloudTrailDetail, error) {
+	/*
+	Function that reads the CloudTrail detail of an EventBridge event.
+
+	:param event: The invocation payload
+	:return: The CloudTrail detail, nil when the payload is not a CloudTrail S3 event, or an error when it cannot be read
+	*/
+
+	if event.Source != sourceS3 || event.DetailType != detailTypeAPICall {
+		return nil, nil
+	}
+
+	var detail cloudTrailDetail
+	if err := json.Unmarshal(event.Detail, &detail); err != nil {
+		return nil, fmt.Errorf("unable to read CloudTrail event detail, %v", err)
+	}
+	return &detail, nil
+}
+
+func eventBucket(detail *cloudTrailDetail) string {
+	/*
+	Function that finds the bucket a CloudTrail event from EventBridge needs remediated.
+
+	'CreateBucket' and 'PutBucketVersioning' calls that suspended versioning name a
+	bucket to check. A failed call, or one that enabled versioning, leaves nothing to do.
+	Other events are logged and leave nothing to do, so an EventBridge rule wider
+	than the handled events does not fail the invocation and get retried.
+
+	:param detail: The CloudTrail detail of the event
+	:return: The name of the bucket to check, empty when there is none
+	*/
+
+	if detail.EventName != "CreateBucket" && detail.EventName != "PutBucketVersioning" {
+		log.Printf("CloudTrail event %q is not handled, no action taken\n", detail.EventName)
+		return ""
+	}
+
+	if detail.ErrorCode != "" {
+		return ""
+	}
+
+	if detail.EventName == "PutBucketVersioning" && detail.RequestParameters.VersioningConfiguration.Status != statusSuspended {
+		return ""
+	}
+	return detail.RequestParameters.BucketName
+}
+
+func eventSuspension(detail *cloudTrailDetail) (string, Suspension, bool) {
+	/*
+	Function that finds the bucket a successful 'PutBucketVersioning' call suspended.
+
+	:param detail: The CloudTrail detail of the event
+	:return: The bucket name, who suspended versioning and when, and whether the event suspended versioning
+	*/
+
+	if detail == nil || detail.ErrorCode != "" || detail.EventName != "PutBucketVersioning" {
+		return "", Suspension{}, false
+	}
+
+	if detail.RequestParameters.VersioningConfiguration.Status != statusSuspended {
+		return "", Suspension{}, false
+	}
+
+	return detail.RequestParameters.BucketName, Suspension{
+		Finding:     findingSuspended,
+		SuspendedBy: detail.UserIdentity.Arn,
+		SuspendedAt: detail.EventTime,
+	}, true
+}
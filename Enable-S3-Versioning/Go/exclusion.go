@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// reasons a bucket is left out of the versioning control
+const (
+	excludedTag       = "exempt-tag"
+	excludedPattern   = "name-pattern"
+	excludedAllowlist = "allowlist"
+)
+
+func (b *Bucket) getBucketTags(bucket string) (map[string]string, error) {
+	/*
+	Private method that reads the tags on an S3 bucket.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:return: A map of tag key to tag value, empty for a bucket with no tags, or an error from AWS
+	*/
+
+	params := &s3.GetBucketTaggingInput {
+		Bucket: aws.String(bucket),
+	}
+
+	tags := make(map[string]string)
+	resp, err := b.clientFor(bucket).GetBucketTagging(context.TODO(), params)
+
+	// S3 returns an error for a bucket without tags
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchTagSet" {
+		return tags, nil
+	}
+
+	if err != nil {
+		return tags, err
+	}
+
+	for _, tag := range resp.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func exclusionReason(bucket string, tags map[string]string, cfg Config) string {
+	/*
+	Function that checks whether a bucket is left out of the versioning control.
+
+	The allowlist and name pattern are checked first, as they do not need the
+	tags of the bucket.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:param tags: The tags on the bucket, nil when they could not be read so only the allowlist and name pattern apply
+	:param cfg: The run settings, used for the exempt tag, name pattern and allowlist
+	:return: The reason the bucket is excluded, or an empty string when it is not
+	*/
+
+	if cfg.Allowlist[bucket] {
+		return excludedAllowlist
+	}
+
+	if cfg.ExcludePattern != nil && cfg.ExcludePattern.MatchString(bucket) {
+		return excludedPattern
+	}
+
+	if exempt, _ := strconv.ParseBool(tags[cfg.ExemptTag]); exempt && cfg.ExemptTag != "" {
+		return excludedTag
+	}
+	return ""
+}
+
+func ownerTags(tags map[string]string, keys []string) map[string]string {
+	/*
+	Function that picks the tags that name the owner of a bucket.
+
+	:param tags: The tags on the bucket
+	:param keys: The tag keys that name an owner, such as 'owner' or 'team'
+	:return: A map of the owner tags found on the bucket, nil when there are none
+	*/
+
+	var owners map[string]string
+	for _, key := range keys {
+		if val, ok := tags[key]; ok {
+			if owners == nil {
+				owners = make(map[string]string)
+			}
+			owners[key] = val
+		}
+	}
+	return owners
+}
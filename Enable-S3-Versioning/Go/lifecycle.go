@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// ID of the lifecycle rule added by this Lambda
+const lifecycleRuleId = "expire-noncurrent-versions"
+
+// outcome of the lifecycle check on a bucket where versioning was enabled
+const (
+	lifecycleAdded    = "rule-added"
+	lifecycleExists   = "rule-exists"
+	lifecycleDisabled = "rule-disabled"
+)
+
+func wantsLifecycle(tags map[string]string, cfg Config) bool {
+	/*
+	Function that checks whether a bucket should get the noncurrent version lifecycle rule.
+
+	:param tags: The tags on the bucket
+	:param cfg: The run settings, used for the global switch and the opt-in tag
+	:return: A bool that is true when the rule is turned on for every bucket or the bucket opts in by tag
+	*/
+
+	if cfg.NoncurrentLifecycle {
+		return true
+	}
+
+	optIn, _ := strconv.ParseBool(tags[cfg.LifecycleTag])
+	return optIn && cfg.LifecycleTag != ""
+}
+
+func bucketWide(rule types.LifecycleRule) bool {
+	/*
+	Function that checks whether a lifecycle rule applies to every object in the bucket.
+
+	:param rule: A lifecycle rule of the bucket
+	:return: A bool that is true when the rule has no prefix, tag or size filter
+	*/
+
+	if aws.ToString(rule.Prefix) != "" {
+		return false
+	}
+
+	f := rule.Filter
+	return f == nil || (aws.ToString(f.Prefix) == "" && f.Tag == nil && f.And == nil &&
+		f.ObjectSizeGreaterThan == nil && f.ObjectSizeLessThan == nil)
+}
+
+func hasNoncurrentExpiry(rules []types.LifecycleRule) bool {
+	/*
+	Function that checks whether the rules already expire noncurrent versions of the whole bucket.
+
+	:param rules: The lifecycle rules of the bucket
+	:return: A bool that is true when an enabled bucket wide rule expires noncurrent versions
+	*/
+
+	for _, rule := range rules {
+		if rule.Status == types.ExpirationStatusEnabled && rule.NoncurrentVersionExpiration != nil && bucketWide(rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func ownRule(rules []types.LifecycleRule) *types.LifecycleRule {
+	/*
+	Function that finds the rule added by this Lambda in the lifecycle of a bucket.
+
+	:param rules: The lifecycle rules of the bucket
+	:return: The rule with the ID of this Lambda, or nil when there is none
+	*/
+
+	for i := range rules {
+		if aws.ToString(rules[i].ID) == lifecycleRuleId {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+func filterForm(rules []types.LifecycleRule) []types.LifecycleRule {
+	/*
+	Function that converts legacy rules with a top level prefix to the same prefix in a filter.
+
+	S3 can reject a configuration that mixes the legacy prefix form with the
+	filter form of the added rule. The prefix has the same meaning in both.
+
+	:param rules: The lifecycle rules of the bucket
+	:return: A copy of the rules, all in the filter form
+	*/
+
+	converted := make([]types.LifecycleRule, len(rules))
+	for i, rule := range rules {
+		if rule.Prefix != nil && rule.Filter == nil {
+			rule.Filter = &types.LifecycleRuleFilter{Prefix: rule.Prefix}
+			rule.Prefix = nil
+		}
+		converted[i] = rule
+	}
+	return converted
+}
+
+func (b *Bucket) ensureLifecycle(bucket string) (string, error) {
+	/*
+	Private method that adds a rule expiring noncurrent versions to the lifecycle of a bucket.
+
+	The existing rules are read first and written back with the new rule, as
+	'PutBucketLifecycleConfiguration' replaces the whole configuration, with
+	legacy prefix rules converted to the filter form. Nothing is written when
+	the noncurrent versions already expire, or when the rule of this Lambda was
+	turned off, which is reported instead.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:return: Either 'rule-added', 'rule-exists' or 'rule-disabled', or an error from AWS
+	*/
+
+	client := b.clientFor(bucket)
+	resp, err := client.GetBucketLifecycleConfiguration(context.TODO(), &s3.GetBucketLifecycleConfigurationInput {
+		Bucket: aws.String(bucket),
+	})
+
+	// S3 returns an error for a bucket without a lifecycle configuration
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchLifecycleConfiguration" {
+		resp, err = &s3.GetBucketLifecycleConfigurationOutput{}, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if rule := ownRule(resp.Rules); rule != nil {
+		if rule.Status != types.ExpirationStatusEnabled {
+			return lifecycleDisabled, nil
+		}
+		return lifecycleExists, nil
+	}
+
+	if hasNoncurrentExpiry(resp.Rules) {
+		return lifecycleExists, nil
+	}
+
+	rules := append(filterForm(resp.Rules), types.LifecycleRule {
+		ID: aws.String(lifecycleRuleId),
+		Status: types.ExpirationStatusEnabled,
+		Filter: &types.LifecycleRuleFilter{Prefix: aws.String("")},
+		NoncurrentVersionExpiration: &types.NoncurrentVersionExpiration {
+			NoncurrentDays: aws.Int32(b.Config.NoncurrentDays),
+		},
+	})
+
+	params := &s3.PutBucketLifecycleConfigurationInput {
+		Bucket: aws.String(bucket),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: rules},
+		TransitionDefaultMinimumObjectSize: resp.TransitionDefaultMinimumObjectSize,
+	}
+
+	if _, err := client.PutBucketLifecycleConfiguration(context.TODO(), params); err != nil {
+		return "", err
+	}
+	return lifecycleAdded, nil
+}
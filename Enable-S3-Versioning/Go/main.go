@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -15,21 +16,65 @@ type S3ActionsApi interface {
 	ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
 	PutBucketVersioning(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error)
 	GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error)
+	GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+	GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error)
+	GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error)
+	PutBucketLifecycleConfiguration(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error)
+	GetObjectLockConfiguration(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error)
+	GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error)
+	PutPublicAccessBlock(ctx context.Context, params *s3.PutPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.PutPublicAccessBlockOutput, error)
+	GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error)
+	PutBucketEncryption(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error)
 }
 
 
-func HandleRequest(ctx context.Context) {
+func HandleRequest(ctx context.Context, event VersioningEvent) (Report, error) {
+
+	report := Report{Buckets: []BucketStatus{}}
+
+	settings, err := loadConfig()
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
 
 	// load the S3 client
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
-        panic("unable to load SDK config, " + err.Error())
+		report.Error = "unable to load SDK config, " + err.Error()
+		return report, fmt.Errorf("unable to load SDK config, %v", err)
 	}
 
 	client := s3.NewFromConfig(cfg)
 
-	b := Bucket{Client: client,}
-	b.Dispatch()
+	// buckets are called through a client for their own region
+	b := Bucket{Client: client, Regional: newRegionalClients(cfg), Config: settings,}
+
+	// a CloudTrail event names the bucket to check, and who suspended versioning on it
+	detail, err := readEvent(event)
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+
+	// scheduled events and manual invocations keep the full sweep, a partial bucket list is reported on the run
+	if detail == nil {
+		statuses, err := b.Dispatch()
+		report.Buckets = statuses
+		if err != nil {
+			report.Error = "listing buckets failed, results are partial: " + err.Error()
+		}
+		return report, nil
+	}
+
+	if bucket, suspension, ok := eventSuspension(detail); ok {
+		b.Suspensions = map[string]Suspension{bucket: suspension}
+	}
+
+	if bucket := eventBucket(detail); bucket != "" {
+		report.Buckets = b.DispatchBucket(bucket, detail.AwsRegion)
+	}
+	return report, nil
 }
 
 func main() {
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// Object Lock state reported when the configuration cannot be read
+const objectLockUnknown = "Unknown"
+
+// findings for a bucket tagged as immutable that lacks a protection
+const (
+	findingNoObjectLock = "object-lock-missing"
+	findingNoMFADelete  = "mfa-delete-missing"
+)
+
+// Object Lock posture of a bucket
+type ObjectLock struct {
+	Status         string `json:"status"`
+	RetentionMode  string `json:"retentionMode,omitempty"`
+	RetentionDays  int32  `json:"retentionDays,omitempty"`
+	RetentionYears int32  `json:"retentionYears,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+func (b *Bucket) getObjectLock(bucket string) (ObjectLock, error) {
+	/*
+	Private method that reads the Object Lock configuration of an S3 bucket.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:return: The Object Lock status with the default retention when one is set, or an error from AWS
+	*/
+
+	params := &s3.GetObjectLockConfigurationInput {
+		Bucket: aws.String(bucket),
+	}
+
+	lock := ObjectLock{Status: statusDisabled}
+	resp, err := b.clientFor(bucket).GetObjectLockConfiguration(context.TODO(), params)
+
+	// S3 returns an error for a bucket created without Object Lock
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ObjectLockConfigurationNotFoundError" {
+		return lock, nil
+	}
+
+	if err != nil {
+		return ObjectLock{Status: objectLockUnknown}, err
+	}
+
+	config := resp.ObjectLockConfiguration
+	if config == nil || config.ObjectLockEnabled == "" {
+		return lock, nil
+	}
+
+	lock.Status = string(config.ObjectLockEnabled)
+	if config.Rule != nil && config.Rule.DefaultRetention != nil {
+		retention := config.Rule.DefaultRetention
+		lock.RetentionMode = string(retention.Mode)
+		lock.RetentionDays = aws.ToInt32(retention.Days)
+		lock.RetentionYears = aws.ToInt32(retention.Years)
+	}
+	return lock, nil
+}
+
+func immutableFindings(status BucketStatus, cfg Config) []string {
+	/*
+	Function that checks a bucket tagged as immutable has Object Lock and MFA Delete.
+
+	This is report only, MFA Delete can only be turned on with root credentials.
+	No Object Lock finding is raised when its configuration could not be read,
+	the error is reported on the Object Lock status instead.
+
+	:param status: The status record of the bucket, with its tags and posture
+	:param cfg: The run settings, used for the immutable tag
+	:return: A slice of the protections the bucket lacks, nil when it is not tagged as immutable
+	*/
+
+	if immutable, _ := strconv.ParseBool(status.tags[cfg.ImmutableTag]); !immutable || cfg.ImmutableTag == "" {
+		return nil
+	}
+
+	findings := []string{}
+	if status.ObjectLock.Status != "Enabled" && status.ObjectLock.Status != objectLockUnknown {
+		findings = append(findings, findingNoObjectLock)
+	}
+
+	if status.MFADelete != statusEnabled {
+		findings = append(findings, findingNoMFADelete)
+	}
+	return findings
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// actions taken on the Block Public Access settings of a bucket
+const (
+	publicAccessNone    = "none"
+	publicAccessBlocked = "block-public-access"
+	publicAccessExempt  = "exempt"
+)
+
+// bucket level Block Public Access settings and the action taken on them
+type PublicAccessStatus struct {
+	BlockPublicAcls       bool   `json:"blockPublicAcls"`
+	IgnorePublicAcls      bool   `json:"ignorePublicAcls"`
+	BlockPublicPolicy     bool   `json:"blockPublicPolicy"`
+	RestrictPublicBuckets bool   `json:"restrictPublicBuckets"`
+	Action                string `json:"action"`
+	Error                 string `json:"error,omitempty"`
+}
+
+func (p PublicAccessStatus) allBlocked() bool {
+	/*
+	Method that checks all four Block Public Access flags are set.
+
+	:return: A bool that is true when every flag is set
+	*/
+
+	return p.BlockPublicAcls && p.IgnorePublicAcls && p.BlockPublicPolicy && p.RestrictPublicBuckets
+}
+
+func (b *Bucket) getPublicAccessBlock(bucket string) (PublicAccessStatus, error) {
+	/*
+	Private method that reads the Block Public Access settings of an S3 bucket.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:return: The four flags, all unset for a bucket without settings, or an error from AWS
+	*/
+
+	params := &s3.GetPublicAccessBlockInput {
+		Bucket: aws.String(bucket),
+	}
+
+	status := PublicAccessStatus{Action: publicAccessNone}
+	resp, err := b.clientFor(bucket).GetPublicAccessBlock(context.TODO(), params)
+
+	// S3 returns an error for a bucket that has never had the settings
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchPublicAccessBlockConfiguration" {
+		return status, nil
+	}
+
+	if err != nil {
+		return status, err
+	}
+
+	if config := resp.PublicAccessBlockConfiguration; config != nil {
+		status.BlockPublicAcls = aws.ToBool(config.BlockPublicAcls)
+		status.IgnorePublicAcls = aws.ToBool(config.IgnorePublicAcls)
+		status.BlockPublicPolicy = aws.ToBool(config.BlockPublicPolicy)
+		status.RestrictPublicBuckets = aws.ToBool(config.RestrictPublicBuckets)
+	}
+	return status, nil
+}
+
+func (b *Bucket) blockPublicAccess(bucket string) error {
+	/*
+	Private method that sets all four Block Public Access flags on an S3 bucket.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:return: nil when run successfully, or an error from AWS
+	*/
+
+	params := &s3.PutPublicAccessBlockInput {
+		Bucket: aws.String(bucket),
+		PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration {
+			BlockPublicAcls: aws.Bool(true),
+			IgnorePublicAcls: aws.Bool(true),
+			BlockPublicPolicy: aws.Bool(true),
+			RestrictPublicBuckets: aws.Bool(true),
+		},
+	}
+
+	_, err := b.clientFor(bucket).PutPublicAccessBlock(context.TODO(), params)
+	return err
+}
+
+func (b *Bucket) enforcePublicAccessBlock(statuses []BucketStatus) {
+	/*
+	Private method that sets Block Public Access on the buckets that lack any of the four flags.
+
+	Buckets with the exempt tag set to 'true', such as public website buckets,
+	are reported and left unchanged. Buckets whose tags could not be read are
+	skipped, as their exemption is not known. The calls are spread over a pool
+	of Config.Concurrency workers and each record is updated in place.
+
+	:param statuses: The status records of the buckets in the struct slice
+	:return: nil
+	*/
+	runWorkers(len(statuses), b.Config.Concurrency, func(i int) {
+		status := &statuses[i]
+		if status.tags == nil {
+			return
+		}
+
+		var access PublicAccessStatus
+		var err error
+		b.inRegion(status.Name, func() {
+			access, err = b.getPublicAccessBlock(status.Name)
+		})
+		status.PublicAccess = &access
+
+		if err != nil {
+			log.Println(err)
+			access.Error = err.Error()
+			return
+		}
+
+		if exempt, _ := strconv.ParseBool(status.tags[b.Config.PublicAccessExemptTag]); exempt {
+			access.Action = publicAccessExempt
+			return
+		}
+
+		if access.allBlocked() {
+			return
+		}
+
+		access.Action = publicAccessBlocked
+		b.inRegion(status.Name, func() {
+			err = b.blockPublicAccess(status.Name)
+		})
+
+		if err != nil {
+			log.Println(err)
+			access.Error = err.Error()
+			return
+		}
+
+		access.BlockPublicAcls, access.IgnorePublicAcls = true, true
+		access.BlockPublicPolicy, access.RestrictPublicBuckets = true, true
+		log.Printf("Blocked public access on bucket %v\n", status.Name)
+	})
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// region S3 reports with an empty location constraint
+const defaultRegion = "us-east-1"
+
+// returns the S3 client for a region, buckets must be called through the client of their own region
+type regionalClients func(region string) S3ActionsApi
+
+func newRegionalClients(cfg aws.Config) regionalClients {
+	/*
+	Function that creates a cached factory of regional S3 clients.
+
+	:param cfg: The SDK config the regional clients are built from
+	:return: A function returning the S3 client for a region, one client is built per region
+	*/
+
+	var mu sync.Mutex
+	clients := make(map[string]S3ActionsApi)
+
+	return func(region string) S3ActionsApi {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if client, ok := clients[region]; ok {
+			return client
+		}
+
+		client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.Region = region
+		})
+		clients[region] = client
+		return client
+	}
+}
+
+func locationRegion(constraint string) string {
+	/*
+	Function that converts a bucket location constraint into its region.
+
+	:param constraint: The location constraint returned by 'GetBucketLocation'
+	:return: The region of the bucket, buckets in us-east-1 have no constraint and 'EU' is eu-west-1
+	*/
+
+	switch constraint {
+	case "":
+		return defaultRegion
+	case "EU":
+		return "eu-west-1"
+	}
+	return constraint
+}
+
+func (b *Bucket) bucketRegion(bucket string) (string, error) {
+	/*
+	Private method that looks up the region of a bucket with 'GetBucketLocation'.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:return: The region of the bucket, or an error from AWS when it cannot be found
+	*/
+
+	params := &s3.GetBucketLocationInput {
+		Bucket: aws.String(bucket),
+	}
+
+	resp, err := b.Client.GetBucketLocation(context.TODO(), params)
+	if err != nil {
+		return "", err
+	}
+	return locationRegion(string(resp.LocationConstraint)), nil
+}
+
+func (b *Bucket) lookupRegions() {
+	/*
+	Private method that finds the region of the buckets in the struct slice that have none.
+
+	This is done after the listing, so 'ListBuckets' is not held up by the
+	lookups. The lookups are spread over a pool of Config.Concurrency workers,
+	sharing the limit for calls with no known region, and throttled lookups are
+	retried by the SDK, as for every S3 call. A bucket whose location cannot be
+	found keeps the error, so it is reported and not called through the wrong client.
+
+	:return: nil
+	*/
+
+	if b.BucketRegions == nil {
+		b.BucketRegions = make(map[string]string)
+	}
+
+	var missing []string
+	for _, bucket := range b.BucketList {
+		if b.BucketRegions[bucket] == "" {
+			missing = append(missing, bucket)
+		}
+	}
+
+	// results are stored by index and the maps are only written once every lookup is done
+	regions := make([]string, len(missing))
+	errs := make([]error, len(missing))
+
+	runWorkers(len(missing), b.Config.Concurrency, func(i int) {
+		b.inRegion(missing[i], func() {
+			regions[i], errs[i] = b.bucketRegion(missing[i])
+		})
+	})
+
+	for i, bucket := range missing {
+		if errs[i] != nil {
+			log.Println(errs[i])
+			if b.regionErrors == nil {
+				b.regionErrors = make(map[string]error)
+			}
+			b.regionErrors[bucket] = errs[i]
+			continue
+		}
+		b.BucketRegions[bucket] = regions[i]
+	}
+}
+
+func (b *Bucket) clientFor(bucket string) S3ActionsApi {
+	/*
+	Private method that returns the S3 client for the region of a bucket.
+
+	The default client is used when there are no regional clients or the region
+	of the bucket is not known.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:return: The S3 client to call for the bucket
+	*/
+
+	region := b.BucketRegions[bucket]
+	if b.Regional == nil || region == "" {
+		return b.Client
+	}
+	return b.Regional(region)
+}
+
+func (b *Bucket) bucketsByRegion() ([]string, map[string][]string) {
+	/*
+	Private method that groups the buckets by their region.
+
+	:return: The sorted regions, and a map of region to the bucket names in that region
+	*/
+
+	groups := make(map[string][]string)
+	for _, bucket := range b.BucketList {
+		region := b.BucketRegions[bucket]
+		groups[region] = append(groups[region], bucket)
+	}
+
+	regions := make([]string, 0, len(groups))
+	for region := range groups {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions, groups
+}
+
+// caps the calls in flight per region, so a region with many buckets is not throttled
+type regionLimiter struct {
+	mu    sync.Mutex
+	limit int
+	slots map[string]chan struct{}
+}
+
+func newRegionLimiter(limit int) *regionLimiter {
+	/*
+	Function that creates a limiter allowing limit calls at once in each region.
+
+	:param limit: The maximum number of calls in flight per region
+	:return: A limiter with no regions in use
+	*/
+
+	if limit < 1 {
+		limit = 1
+	}
+	return &regionLimiter{limit: limit, slots: make(map[string]chan struct{})}
+}
+
+func (l *regionLimiter) do(region string, fn func()) {
+	/*
+	Private method that calls fn once a slot for the region is free.
+
+	:param region: The region the call is made in
+	:param fn: The function making the call
+	:return: None, returns once fn has returned
+	*/
+
+	l.mu.Lock()
+	slot, ok := l.slots[region]
+	if !ok {
+		slot = make(chan struct{}, l.limit)
+		l.slots[region] = slot
+	}
+	l.mu.Unlock()
+
+	slot <- struct{}{}
+	defer func() { <-slot }()
+	fn()
+}
+
+func (b *Bucket) inRegion(bucket string, fn func()) {
+	/*
+	Private method that makes a call for a bucket within the limit of its region.
+
+	The call is made straight away when there is no limiter.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:param fn: The function making the call
+	:return: None, returns once fn has returned
+	*/
+
+	if b.limiter == nil {
+		fn()
+		return
+	}
+	b.limiter.do(b.BucketRegions[bucket], fn)
+}
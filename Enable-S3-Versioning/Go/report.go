@@ -0,0 +1,72 @@
+package main
+
+// versioning states of a bucket, S3 returns no status for a bucket that never had versioning
+const (
+	statusEnabled   = "Enabled"
+	statusSuspended = "Suspended"
+	statusDisabled  = "Disabled"
+)
+
+// actions taken on a bucket
+const (
+	actionNone             = "none"
+	actionEnableVersioning = "enable-versioning"
+	actionAlert            = "alert"
+)
+
+// finding raised for a bucket where versioning was deliberately turned off
+const findingSuspended = "versioning-suspended"
+
+// suspension of versioning on a bucket, who and when are only known from a CloudTrail event
+type Suspension struct {
+	Finding     string `json:"finding"`
+	SuspendedBy string `json:"suspendedBy,omitempty"`
+	SuspendedAt string `json:"suspendedAt,omitempty"`
+}
+
+// result of a run, the error is set when listing the buckets failed and the records are partial
+type Report struct {
+	Buckets []BucketStatus `json:"buckets"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// versioning status of a bucket and the action taken on it
+type BucketStatus struct {
+	Name         string              `json:"name"`
+	Region       string              `json:"region"`
+	Versioning   string              `json:"versioning"`
+	MFADelete    string              `json:"mfaDelete"`
+	ObjectLock   ObjectLock          `json:"objectLock"`
+	Immutable    []string            `json:"immutableFindings,omitempty"`
+	OwnerTags    map[string]string   `json:"ownerTags,omitempty"`
+	TagError     string              `json:"tagError,omitempty"`
+	Suspension   *Suspension         `json:"suspension,omitempty"`
+	Excluded     string              `json:"excluded,omitempty"`
+	Lifecycle    string              `json:"lifecycle,omitempty"`
+	PublicAccess *PublicAccessStatus `json:"publicAccessBlock,omitempty"`
+	Encryption   *EncryptionStatus   `json:"encryption,omitempty"`
+	Action       string              `json:"action"`
+	Error        string              `json:"error,omitempty"`
+
+	// tags read from the bucket, kept for the checks and not reported
+	tags map[string]string
+}
+
+func newBucketStatus(bucket string, region string) BucketStatus {
+	/*
+	Function that creates the status record of a bucket before it is checked.
+
+	:param bucket: (required) A string containing the name of the S3 bucket
+	:param region: The region of the bucket
+	:return: A status record with no action taken
+	*/
+
+	return BucketStatus{
+		Name:       bucket,
+		Region:     region,
+		Versioning: statusDisabled,
+		MFADelete:  statusDisabled,
+		ObjectLock: ObjectLock{Status: statusDisabled},
+		Action:     actionNone,
+	}
+}
@@ -19,15 +19,42 @@ var _ S3ActionsApi = &S3ActionsApiMock{}
 //
 //		// make and configure a mocked S3ActionsApi
 //		mockedS3ActionsApi := &S3ActionsApiMock{
+//			GetBucketEncryptionFunc: func(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+//				panic("mock out the GetBucketEncryption method")
+//			},
+//			GetBucketLifecycleConfigurationFunc: func(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+//				panic("mock out the GetBucketLifecycleConfiguration method")
+//			},
+//			GetBucketLocationFunc: func(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+//				panic("mock out the GetBucketLocation method")
+//			},
+//			GetBucketTaggingFunc: func(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+//				panic("mock out the GetBucketTagging method")
+//			},
 //			GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
 //				panic("mock out the GetBucketVersioning method")
 //			},
+//			GetObjectLockConfigurationFunc: func(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+//				panic("mock out the GetObjectLockConfiguration method")
+//			},
+//			GetPublicAccessBlockFunc: func(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error) {
+//				panic("mock out the GetPublicAccessBlock method")
+//			},
 //			ListBucketsFunc: func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 //				panic("mock out the ListBuckets method")
 //			},
+//			PutBucketEncryptionFunc: func(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error) {
+//				panic("mock out the PutBucketEncryption method")
+//			},
+//			PutBucketLifecycleConfigurationFunc: func(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+//				panic("mock out the PutBucketLifecycleConfiguration method")
+//			},
 //			PutBucketVersioningFunc: func(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
 //				panic("mock out the PutBucketVersioning method")
 //			},
+//			PutPublicAccessBlockFunc: func(ctx context.Context, params *s3.PutPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.PutPublicAccessBlockOutput, error) {
+//				panic("mock out the PutPublicAccessBlock method")
+//			},
 //		}
 //
 //		// use mockedS3ActionsApi in code that requires S3ActionsApi
@@ -35,17 +62,80 @@ var _ S3ActionsApi = &S3ActionsApiMock{}
 //
 //	}
 type S3ActionsApiMock struct {
+	// GetBucketEncryptionFunc mocks the GetBucketEncryption method.
+	GetBucketEncryptionFunc func(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error)
+
+	// GetBucketLifecycleConfigurationFunc mocks the GetBucketLifecycleConfiguration method.
+	GetBucketLifecycleConfigurationFunc func(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error)
+
+	// GetBucketLocationFunc mocks the GetBucketLocation method.
+	GetBucketLocationFunc func(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+
+	// GetBucketTaggingFunc mocks the GetBucketTagging method.
+	GetBucketTaggingFunc func(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error)
+
 	// GetBucketVersioningFunc mocks the GetBucketVersioning method.
 	GetBucketVersioningFunc func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error)
 
+	// GetObjectLockConfigurationFunc mocks the GetObjectLockConfiguration method.
+	GetObjectLockConfigurationFunc func(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error)
+
+	// GetPublicAccessBlockFunc mocks the GetPublicAccessBlock method.
+	GetPublicAccessBlockFunc func(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error)
+
 	// ListBucketsFunc mocks the ListBuckets method.
 	ListBucketsFunc func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
 
+	// PutBucketEncryptionFunc mocks the PutBucketEncryption method.
+	PutBucketEncryptionFunc func(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error)
+
+	// PutBucketLifecycleConfigurationFunc mocks the PutBucketLifecycleConfiguration method.
+	PutBucketLifecycleConfigurationFunc func(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error)
+
 	// PutBucketVersioningFunc mocks the PutBucketVersioning method.
 	PutBucketVersioningFunc func(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error)
 
+	// PutPublicAccessBlockFunc mocks the PutPublicAccessBlock method.
+	PutPublicAccessBlockFunc func(ctx context.Context, params *s3.PutPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.PutPublicAccessBlockOutput, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// GetBucketEncryption holds details about calls to the GetBucketEncryption method.
+		GetBucketEncryption []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.GetBucketEncryptionInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
+		// GetBucketLifecycleConfiguration holds details about calls to the GetBucketLifecycleConfiguration method.
+		GetBucketLifecycleConfiguration []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.GetBucketLifecycleConfigurationInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
+		// GetBucketLocation holds details about calls to the GetBucketLocation method.
+		GetBucketLocation []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.GetBucketLocationInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
+		// GetBucketTagging holds details about calls to the GetBucketTagging method.
+		GetBucketTagging []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.GetBucketTaggingInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
 		// GetBucketVersioning holds details about calls to the GetBucketVersioning method.
 		GetBucketVersioning []struct {
 			// Ctx is the ctx argument value.
@@ -55,6 +145,24 @@ type S3ActionsApiMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*s3.Options)
 		}
+		// GetObjectLockConfiguration holds details about calls to the GetObjectLockConfiguration method.
+		GetObjectLockConfiguration []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.GetObjectLockConfigurationInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
+		// GetPublicAccessBlock holds details about calls to the GetPublicAccessBlock method.
+		GetPublicAccessBlock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.GetPublicAccessBlockInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
 		// ListBuckets holds details about calls to the ListBuckets method.
 		ListBuckets []struct {
 			// Ctx is the ctx argument value.
@@ -64,6 +172,24 @@ type S3ActionsApiMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*s3.Options)
 		}
+		// PutBucketEncryption holds details about calls to the PutBucketEncryption method.
+		PutBucketEncryption []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.PutBucketEncryptionInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
+		// PutBucketLifecycleConfiguration holds details about calls to the PutBucketLifecycleConfiguration method.
+		PutBucketLifecycleConfiguration []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.PutBucketLifecycleConfigurationInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
 		// PutBucketVersioning holds details about calls to the PutBucketVersioning method.
 		PutBucketVersioning []struct {
 			// Ctx is the ctx argument value.
@@ -73,10 +199,188 @@ type S3ActionsApiMock struct {
 			// OptFns is the optFns argument value.
 			OptFns []func(*s3.Options)
 		}
+		// PutPublicAccessBlock holds details about calls to the PutPublicAccessBlock method.
+		PutPublicAccessBlock []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Params is the params argument value.
+			Params *s3.PutPublicAccessBlockInput
+			// OptFns is the optFns argument value.
+			OptFns []func(*s3.Options)
+		}
+	}
+	lockGetBucketEncryption             sync.RWMutex
+	lockGetBucketLifecycleConfiguration sync.RWMutex
+	lockGetBucketLocation               sync.RWMutex
+	lockGetBucketTagging                sync.RWMutex
+	lockGetBucketVersioning             sync.RWMutex
+	lockGetObjectLockConfiguration      sync.RWMutex
+	lockGetPublicAccessBlock            sync.RWMutex
+	lockListBuckets                     sync.RWMutex
+	lockPutBucketEncryption             sync.RWMutex
+	lockPutBucketLifecycleConfiguration sync.RWMutex
+	lockPutBucketVersioning             sync.RWMutex
+	lockPutPublicAccessBlock            sync.RWMutex
+}
+
+// GetBucketEncryption calls GetBucketEncryptionFunc.
+func (mock *S3ActionsApiMock) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	if mock.GetBucketEncryptionFunc == nil {
+		panic("S3ActionsApiMock.GetBucketEncryptionFunc: method is nil but S3ActionsApi.GetBucketEncryption was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.GetBucketEncryptionInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockGetBucketEncryption.Lock()
+	mock.calls.GetBucketEncryption = append(mock.calls.GetBucketEncryption, callInfo)
+	mock.lockGetBucketEncryption.Unlock()
+	return mock.GetBucketEncryptionFunc(ctx, params, optFns...)
+}
+
+// GetBucketEncryptionCalls gets all the calls that were made to GetBucketEncryption.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.GetBucketEncryptionCalls())
+func (mock *S3ActionsApiMock) GetBucketEncryptionCalls() []struct {
+	Ctx    context.Context
+	Params *s3.GetBucketEncryptionInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.GetBucketEncryptionInput
+		OptFns []func(*s3.Options)
+	}
+	mock.lockGetBucketEncryption.RLock()
+	calls = mock.calls.GetBucketEncryption
+	mock.lockGetBucketEncryption.RUnlock()
+	return calls
+}
+
+// GetBucketLifecycleConfiguration calls GetBucketLifecycleConfigurationFunc.
+func (mock *S3ActionsApiMock) GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+	if mock.GetBucketLifecycleConfigurationFunc == nil {
+		panic("S3ActionsApiMock.GetBucketLifecycleConfigurationFunc: method is nil but S3ActionsApi.GetBucketLifecycleConfiguration was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.GetBucketLifecycleConfigurationInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockGetBucketLifecycleConfiguration.Lock()
+	mock.calls.GetBucketLifecycleConfiguration = append(mock.calls.GetBucketLifecycleConfiguration, callInfo)
+	mock.lockGetBucketLifecycleConfiguration.Unlock()
+	return mock.GetBucketLifecycleConfigurationFunc(ctx, params, optFns...)
+}
+
+// GetBucketLifecycleConfigurationCalls gets all the calls that were made to GetBucketLifecycleConfiguration.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.GetBucketLifecycleConfigurationCalls())
+func (mock *S3ActionsApiMock) GetBucketLifecycleConfigurationCalls() []struct {
+	Ctx    context.Context
+	Params *s3.GetBucketLifecycleConfigurationInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.GetBucketLifecycleConfigurationInput
+		OptFns []func(*s3.Options)
 	}
-	lockGetBucketVersioning sync.RWMutex
-	lockListBuckets         sync.RWMutex
-	lockPutBucketVersioning sync.RWMutex
+	mock.lockGetBucketLifecycleConfiguration.RLock()
+	calls = mock.calls.GetBucketLifecycleConfiguration
+	mock.lockGetBucketLifecycleConfiguration.RUnlock()
+	return calls
+}
+
+// GetBucketLocation calls GetBucketLocationFunc.
+func (mock *S3ActionsApiMock) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	if mock.GetBucketLocationFunc == nil {
+		panic("S3ActionsApiMock.GetBucketLocationFunc: method is nil but S3ActionsApi.GetBucketLocation was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.GetBucketLocationInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockGetBucketLocation.Lock()
+	mock.calls.GetBucketLocation = append(mock.calls.GetBucketLocation, callInfo)
+	mock.lockGetBucketLocation.Unlock()
+	return mock.GetBucketLocationFunc(ctx, params, optFns...)
+}
+
+// GetBucketLocationCalls gets all the calls that were made to GetBucketLocation.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.GetBucketLocationCalls())
+func (mock *S3ActionsApiMock) GetBucketLocationCalls() []struct {
+	Ctx    context.Context
+	Params *s3.GetBucketLocationInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.GetBucketLocationInput
+		OptFns []func(*s3.Options)
+	}
+	mock.lockGetBucketLocation.RLock()
+	calls = mock.calls.GetBucketLocation
+	mock.lockGetBucketLocation.RUnlock()
+	return calls
+}
+
+// GetBucketTagging calls GetBucketTaggingFunc.
+func (mock *S3ActionsApiMock) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	if mock.GetBucketTaggingFunc == nil {
+		panic("S3ActionsApiMock.GetBucketTaggingFunc: method is nil but S3ActionsApi.GetBucketTagging was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.GetBucketTaggingInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockGetBucketTagging.Lock()
+	mock.calls.GetBucketTagging = append(mock.calls.GetBucketTagging, callInfo)
+	mock.lockGetBucketTagging.Unlock()
+	return mock.GetBucketTaggingFunc(ctx, params, optFns...)
+}
+
+// GetBucketTaggingCalls gets all the calls that were made to GetBucketTagging.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.GetBucketTaggingCalls())
+func (mock *S3ActionsApiMock) GetBucketTaggingCalls() []struct {
+	Ctx    context.Context
+	Params *s3.GetBucketTaggingInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.GetBucketTaggingInput
+		OptFns []func(*s3.Options)
+	}
+	mock.lockGetBucketTagging.RLock()
+	calls = mock.calls.GetBucketTagging
+	mock.lockGetBucketTagging.RUnlock()
+	return calls
 }
 
 // GetBucketVersioning calls GetBucketVersioningFunc.
@@ -119,6 +423,86 @@ func (mock *S3ActionsApiMock) GetBucketVersioningCalls() []struct {
 	return calls
 }
 
+// GetObjectLockConfiguration calls GetObjectLockConfigurationFunc.
+func (mock *S3ActionsApiMock) GetObjectLockConfiguration(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+	if mock.GetObjectLockConfigurationFunc == nil {
+		panic("S3ActionsApiMock.GetObjectLockConfigurationFunc: method is nil but S3ActionsApi.GetObjectLockConfiguration was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.GetObjectLockConfigurationInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockGetObjectLockConfiguration.Lock()
+	mock.calls.GetObjectLockConfiguration = append(mock.calls.GetObjectLockConfiguration, callInfo)
+	mock.lockGetObjectLockConfiguration.Unlock()
+	return mock.GetObjectLockConfigurationFunc(ctx, params, optFns...)
+}
+
+// GetObjectLockConfigurationCalls gets all the calls that were made to GetObjectLockConfiguration.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.GetObjectLockConfigurationCalls())
+func (mock *S3ActionsApiMock) GetObjectLockConfigurationCalls() []struct {
+	Ctx    context.Context
+	Params *s3.GetObjectLockConfigurationInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.GetObjectLockConfigurationInput
+		OptFns []func(*s3.Options)
+	}
+	mock.lockGetObjectLockConfiguration.RLock()
+	calls = mock.calls.GetObjectLockConfiguration
+	mock.lockGetObjectLockConfiguration.RUnlock()
+	return calls
+}
+
+// GetPublicAccessBlock calls GetPublicAccessBlockFunc.
+func (mock *S3ActionsApiMock) GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error) {
+	if mock.GetPublicAccessBlockFunc == nil {
+		panic("S3ActionsApiMock.GetPublicAccessBlockFunc: method is nil but S3ActionsApi.GetPublicAccessBlock was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.GetPublicAccessBlockInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockGetPublicAccessBlock.Lock()
+	mock.calls.GetPublicAccessBlock = append(mock.calls.GetPublicAccessBlock, callInfo)
+	mock.lockGetPublicAccessBlock.Unlock()
+	return mock.GetPublicAccessBlockFunc(ctx, params, optFns...)
+}
+
+// GetPublicAccessBlockCalls gets all the calls that were made to GetPublicAccessBlock.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.GetPublicAccessBlockCalls())
+func (mock *S3ActionsApiMock) GetPublicAccessBlockCalls() []struct {
+	Ctx    context.Context
+	Params *s3.GetPublicAccessBlockInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.GetPublicAccessBlockInput
+		OptFns []func(*s3.Options)
+	}
+	mock.lockGetPublicAccessBlock.RLock()
+	calls = mock.calls.GetPublicAccessBlock
+	mock.lockGetPublicAccessBlock.RUnlock()
+	return calls
+}
+
 // ListBuckets calls ListBucketsFunc.
 func (mock *S3ActionsApiMock) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
 	if mock.ListBucketsFunc == nil {
@@ -159,6 +543,86 @@ func (mock *S3ActionsApiMock) ListBucketsCalls() []struct {
 	return calls
 }
 
+// PutBucketEncryption calls PutBucketEncryptionFunc.
+func (mock *S3ActionsApiMock) PutBucketEncryption(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error) {
+	if mock.PutBucketEncryptionFunc == nil {
+		panic("S3ActionsApiMock.PutBucketEncryptionFunc: method is nil but S3ActionsApi.PutBucketEncryption was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.PutBucketEncryptionInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockPutBucketEncryption.Lock()
+	mock.calls.PutBucketEncryption = append(mock.calls.PutBucketEncryption, callInfo)
+	mock.lockPutBucketEncryption.Unlock()
+	return mock.PutBucketEncryptionFunc(ctx, params, optFns...)
+}
+
+// PutBucketEncryptionCalls gets all the calls that were made to PutBucketEncryption.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.PutBucketEncryptionCalls())
+func (mock *S3ActionsApiMock) PutBucketEncryptionCalls() []struct {
+	Ctx    context.Context
+	Params *s3.PutBucketEncryptionInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.PutBucketEncryptionInput
+		OptFns []func(*s3.Options)
+	}
+	mock.lockPutBucketEncryption.RLock()
+	calls = mock.calls.PutBucketEncryption
+	mock.lockPutBucketEncryption.RUnlock()
+	return calls
+}
+
+// PutBucketLifecycleConfiguration calls PutBucketLifecycleConfigurationFunc.
+func (mock *S3ActionsApiMock) PutBucketLifecycleConfiguration(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	if mock.PutBucketLifecycleConfigurationFunc == nil {
+		panic("S3ActionsApiMock.PutBucketLifecycleConfigurationFunc: method is nil but S3ActionsApi.PutBucketLifecycleConfiguration was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.PutBucketLifecycleConfigurationInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockPutBucketLifecycleConfiguration.Lock()
+	mock.calls.PutBucketLifecycleConfiguration = append(mock.calls.PutBucketLifecycleConfiguration, callInfo)
+	mock.lockPutBucketLifecycleConfiguration.Unlock()
+	return mock.PutBucketLifecycleConfigurationFunc(ctx, params, optFns...)
+}
+
+// PutBucketLifecycleConfigurationCalls gets all the calls that were made to PutBucketLifecycleConfiguration.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.PutBucketLifecycleConfigurationCalls())
+func (mock *S3ActionsApiMock) PutBucketLifecycleConfigurationCalls() []struct {
+	Ctx    context.Context
+	Params *s3.PutBucketLifecycleConfigurationInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.PutBucketLifecycleConfigurationInput
+		OptFns []func(*s3.Options)
+	}
+	mock.lockPutBucketLifecycleConfiguration.RLock()
+	calls = mock.calls.PutBucketLifecycleConfiguration
+	mock.lockPutBucketLifecycleConfiguration.RUnlock()
+	return calls
+}
+
 // PutBucketVersioning calls PutBucketVersioningFunc.
 func (mock *S3ActionsApiMock) PutBucketVersioning(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
 	if mock.PutBucketVersioningFunc == nil {
@@ -198,3 +662,43 @@ func (mock *S3ActionsApiMock) PutBucketVersioningCalls() []struct {
 	mock.lockPutBucketVersioning.RUnlock()
 	return calls
 }
+
+// PutPublicAccessBlock calls PutPublicAccessBlockFunc.
+func (mock *S3ActionsApiMock) PutPublicAccessBlock(ctx context.Context, params *s3.PutPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.PutPublicAccessBlockOutput, error) {
+	if mock.PutPublicAccessBlockFunc == nil {
+		panic("S3ActionsApiMock.PutPublicAccessBlockFunc: method is nil but S3ActionsApi.PutPublicAccessBlock was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Params *s3.PutPublicAccessBlockInput
+		OptFns []func(*s3.Options)
+	}{
+		Ctx:    ctx,
+		Params: params,
+		OptFns: optFns,
+	}
+	mock.lockPutPublicAccessBlock.Lock()
+	mock.calls.PutPublicAccessBlock = append(mock.calls.PutPublicAccessBlock, callInfo)
+	mock.lockPutPublicAccessBlock.Unlock()
+	return mock.PutPublicAccessBlockFunc(ctx, params, optFns...)
+}
+
+// PutPublicAccessBlockCalls gets all the calls that were made to PutPublicAccessBlock.
+// Check the length with:
+//
+//	len(mockedS3ActionsApi.PutPublicAccessBlockCalls())
+func (mock *S3ActionsApiMock) PutPublicAccessBlockCalls() []struct {
+	Ctx    context.Context
+	Params *s3.PutPublicAccessBlockInput
+	OptFns []func(*s3.Options)
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Params *s3.PutPublicAccessBlockInput
+		OptFns []func(*s3.Options)
+	}
+	mock.lockPutPublicAccessBlock.RLock()
+	calls = mock.calls.PutPublicAccessBlock
+	mock.lockPutPublicAccessBlock.RUnlock()
+	return calls
+}
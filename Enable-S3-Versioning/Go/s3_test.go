@@ -3,14 +3,33 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"gotest.tools/assert"
 )
 
 
+func getBucketTaggingMock(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	// S3 returns 'NoSuchTagSet' for a bucket without tags
+	return nil, &smithy.GenericAPIError{Code: "NoSuchTagSet"}
+}
+
+func getObjectLockMock(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+	// S3 returns 'ObjectLockConfigurationNotFoundError' for a bucket without Object Lock
+	return nil, &smithy.GenericAPIError{Code: "ObjectLockConfigurationNotFoundError"}
+}
+
 func TestListBuckets(t *testing.T) {
 	/*
 	This test is used to test the functionality of the 'bucketList' method.
@@ -42,7 +61,7 @@ func TestPutBucketVersioning(t *testing.T) {
 	/*
 	This test is used to test the functionality of the 'updateBucketVersion' method.
 
-	This will assert that the method returns a boolean 'true' if successfully run.
+	This will assert that the method returns no error if successfully run.
 	*/
 	
 	mockedS3ActionsApi := &S3ActionsApiMock{
@@ -56,8 +75,8 @@ func TestPutBucketVersioning(t *testing.T) {
 		},
 	}
 	b := Bucket{Client: mockedS3ActionsApi}
-	result := b.updateBucketVersion("bucket1")
-	assert.Equal(t, true, result)
+	err := b.updateBucketVersion("bucket1")
+	assert.NilError(t, err)
 
 }
 
@@ -91,11 +110,12 @@ func TestCheckBucketVersion(t *testing.T) {
 	/*
 	This test is used to test the functionality of the 'checkBucketVersion' method.
 
-	This will assert that the method returns a map containing a string key and key.
-	The key will will be the versioning status (i.e., "enabled", "suspended", "disabled")
-	plus the index of the bucket name in the struct slice.
+	This will assert that the method returns a status record for every bucket
+	in the order of the struct slice, with the versioning and MFA delete status.
 	*/
 	mockedS3ActionsApi := &S3ActionsApiMock{
+		GetBucketTaggingFunc: getBucketTaggingMock,
+		GetObjectLockConfigurationFunc: getObjectLockMock,
 		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
 			
 			var s3Output s3.GetBucketVersioningOutput
@@ -113,40 +133,11 @@ func TestCheckBucketVersion(t *testing.T) {
 	b.BucketList = append(b.BucketList, "bucket1")
 	b.BucketList = append(b.BucketList, "bucket2")
 	result := b.checkBucketVersion()
-	assert.Equal(t, "bucket2", result["enabled1"])
-}
-
-func TestRemoveItemFromDict(t *testing.T){
-	/*
-	This test is used to test the functionality of the 'removeItemFromDict' method.
-
-	This will assert that the method returns a boolean value depending on whether 
-	the item is removed from the map.
-	*/
-	mockedS3ActionsApi := &S3ActionsApiMock{
-		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
-			
-			var s3Output s3.GetBucketVersioningOutput
-			// read a json file and return the content for mock
-			data, _ := ioutil.ReadFile("test_data/get-bucket-versioning-data.json")
-
-			// this unmarshal will convert the json data into a usable mock format
-			json.Unmarshal(data, &s3Output);
-			return &s3Output,nil;
-
-		},
-	}
-
-	b := Bucket{Client: mockedS3ActionsApi}
-	b.BucketList = append(b.BucketList, "bucket1")
-	b.BucketList = append(b.BucketList, "bucket2")
-	
-	bucketVersion := b.checkBucketVersion()
-	result1 := b.removeItemFromDict("enabled0", bucketVersion)
-	result2 := b.removeItemFromDict("enabled", bucketVersion)
-	assert.Equal(t, true, result1)
-	assert.Equal(t, false, result2)
-
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, "bucket2", result[1].Name)
+	assert.Equal(t, statusEnabled, result[1].Versioning)
+	assert.Equal(t, statusDisabled, result[1].MFADelete)
+	assert.Equal(t, actionNone, result[1].Action)
 }
 
 func TestDispatch(t *testing.T) {
@@ -172,6 +163,8 @@ func TestDispatch(t *testing.T) {
 			return &s3Output,nil;
 
 		},
+		GetBucketTaggingFunc: getBucketTaggingMock,
+		GetObjectLockConfigurationFunc: getObjectLockMock,
 		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
 			
 			var s3Output s3.GetBucketVersioningOutput
@@ -187,6 +180,624 @@ func TestDispatch(t *testing.T) {
 	b := Bucket{Client: mockedS3ActionsApi}
 	b.BucketList = append(b.BucketList, "bucket2")
 	b.BucketList = append(b.BucketList, "bucket3")
-	b.Dispatch()
+	result, _ := b.Dispatch()
+
+	// the two buckets set on the struct come first, and are not added again when listed
+	assert.Equal(t, 3, len(result))
+	assert.Equal(t, "bucket2", result[0].Name)
+	assert.Equal(t, "bucket3", result[1].Name)
+	assert.Equal(t, "bucket1", result[2].Name)
+	assert.Equal(t, "eu-west-1", result[0].Region)
+	for _, status := range result {
+		assert.Equal(t, statusDisabled, status.Versioning)
+		assert.Equal(t, actionEnableVersioning, status.Action)
+		assert.Equal(t, "", status.Error)
+	}
+	assert.Equal(t, 3, len(mockedS3ActionsApi.PutBucketVersioningCalls()))
+}
+func TestBucketRegions(t *testing.T) {
+	/*
+	This test is used to test the region handling of the 'bucketList' method.
+
+	This will assert that every page of buckets is read, that the location is
+	looked up for a bucket without a region, and that calls for a bucket go
+	through the client for its region.
+	*/
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		ListBucketsFunc: func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+
+			// the first page points to a second page with the bucket without a region
+			if params.ContinuationToken == nil {
+				return &s3.ListBucketsOutput{
+					Buckets: []types.Bucket{{Name: aws.String("bucket1"), BucketRegion: aws.String("us-west-2")}},
+					ContinuationToken: aws.String("page2"),
+				}, nil
+			}
+			return &s3.ListBucketsOutput{Buckets: []types.Bucket{{Name: aws.String("bucket2")}}}, nil
+
+		},
+		GetBucketLocationFunc: func(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+			return &s3.GetBucketLocationOutput{LocationConstraint: types.BucketLocationConstraintEu}, nil
+		},
+	}
+
+	var regions []string
+	regionalS3ActionsApi := &S3ActionsApiMock{
+		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+			return &s3.GetBucketVersioningOutput{Status: types.BucketVersioningStatusEnabled}, nil
+		},
+	}
+
+	b := Bucket{Client: mockedS3ActionsApi, Regional: func(region string) S3ActionsApi {
+		regions = append(regions, region)
+		return regionalS3ActionsApi
+	}}
+	b.bucketList()
+	b.lookupRegions()
+
+	assert.DeepEqual(t, []string{"bucket1", "bucket2"}, b.BucketList)
+	assert.Equal(t, "us-west-2", b.BucketRegions["bucket1"])
+	assert.Equal(t, "eu-west-1", b.BucketRegions["bucket2"])
+	assert.Equal(t, 1, len(mockedS3ActionsApi.GetBucketLocationCalls()))
 
-}
\ No newline at end of file
+	b.getBucketVersion("bucket2")
+	assert.DeepEqual(t, []string{"eu-west-1"}, regions)
+	assert.Equal(t, 0, len(mockedS3ActionsApi.GetBucketVersioningCalls()))
+}
+
+func TestListingErrors(t *testing.T) {
+	/*
+	This test is used to test the error handling of the 'Dispatch' method.
+
+	This will assert that a failed second page of 'ListBuckets' is returned as an
+	error with the buckets of the first page still checked, and that a bucket
+	whose location cannot be found is reported with the error and not changed.
+	*/
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		ListBucketsFunc: func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+			if params.ContinuationToken == nil {
+				return &s3.ListBucketsOutput{
+					Buckets: []types.Bucket{{Name: aws.String("bucket1"), BucketRegion: aws.String("us-west-2")}, {Name: aws.String("bucket2")}},
+					ContinuationToken: aws.String("page2"),
+				}, nil
+			}
+			return nil, &smithy.GenericAPIError{Code: "InternalError"}
+		},
+		GetBucketLocationFunc: func(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+			return nil, &smithy.GenericAPIError{Code: "AccessDenied"}
+		},
+		GetBucketTaggingFunc: getBucketTaggingMock,
+		GetObjectLockConfigurationFunc: getObjectLockMock,
+		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+			return &s3.GetBucketVersioningOutput{}, nil
+		},
+		PutBucketVersioningFunc: func(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
+			return &s3.PutBucketVersioningOutput{}, nil
+		},
+	}
+
+	b := Bucket{Client: mockedS3ActionsApi}
+	err := b.bucketList()
+	b.lookupRegions()
+
+	assert.ErrorContains(t, err, "InternalError")
+	assert.DeepEqual(t, []string{"bucket1", "bucket2"}, b.BucketList)
+	assert.ErrorContains(t, b.regionErrors["bucket2"], "AccessDenied")
+
+	b = Bucket{Client: mockedS3ActionsApi}
+	result, err := b.Dispatch()
+
+	assert.ErrorContains(t, err, "InternalError")
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, actionEnableVersioning, result[0].Action)
+	assert.Equal(t, "", result[0].Error)
+	assert.Equal(t, actionNone, result[1].Action)
+	assert.Assert(t, strings.HasPrefix(result[1].Error, "unable to find bucket region"))
+
+	calls := mockedS3ActionsApi.PutBucketVersioningCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, "bucket1", *calls[0].Params.Bucket)
+}
+
+func TestConcurrentChecks(t *testing.T) {
+	/*
+	This test is used to test the worker pool of the 'Dispatch' method.
+
+	This will assert that the records keep the order of the listed buckets,
+	and that no more calls than the region limit are in flight in a region.
+	*/
+	var buckets []types.Bucket
+	for i := 0; i < 40; i++ {
+		region := []string{"us-east-1", "eu-west-1"}[i%2]
+		buckets = append(buckets, types.Bucket{Name: aws.String(fmt.Sprintf("bucket%02d", i)), BucketRegion: aws.String(region)})
+	}
+
+	var mu sync.Mutex
+	inFlight := make(map[string]int)
+	maxInFlight := 0
+
+	// tracks the calls in flight in the region of the client
+	track := func(region string) func() {
+		mu.Lock()
+		inFlight[region]++
+		if inFlight[region] > maxInFlight {
+			maxInFlight = inFlight[region]
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+		return func() {
+			mu.Lock()
+			inFlight[region]--
+			mu.Unlock()
+		}
+	}
+
+	regional := func(region string) S3ActionsApi {
+		return &S3ActionsApiMock{
+			GetBucketTaggingFunc: getBucketTaggingMock,
+			GetObjectLockConfigurationFunc: getObjectLockMock,
+			GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+				defer track(region)()
+				return &s3.GetBucketVersioningOutput{Status: types.BucketVersioningStatusSuspended}, nil
+			},
+			PutBucketVersioningFunc: func(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
+				defer track(region)()
+				return &s3.PutBucketVersioningOutput{}, nil
+			},
+		}
+	}
+
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		ListBucketsFunc: func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+			return &s3.ListBucketsOutput{Buckets: buckets}, nil
+		},
+	}
+
+	b := Bucket{Client: mockedS3ActionsApi, Regional: regional, Config: Config{Concurrency: 8, RegionConcurrency: 2}}
+	result, _ := b.Dispatch()
+
+	assert.Equal(t, 40, len(result))
+	for i, status := range result {
+		assert.Equal(t, *buckets[i].Name, status.Name)
+		assert.Equal(t, actionEnableVersioning, status.Action)
+	}
+	assert.Assert(t, maxInFlight <= 2)
+}
+
+func TestVersioningPolicies(t *testing.T) {
+	/*
+	This test is used to test the per state policies of the 'Dispatch' method.
+
+	This will assert that a suspended bucket gets a finding with who suspended
+	it from the event, and that only the state set to remediate is changed.
+	*/
+	var event VersioningEvent
+	data, _ := ioutil.ReadFile("test_data/event-suspend-versioning.json")
+	json.Unmarshal(data, &event);
+
+	detail, err := readEvent(event)
+	assert.NilError(t, err)
+	bucket, suspension, ok := eventSuspension(detail)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "bucket2", bucket)
+
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		ListBucketsFunc: func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+			return &s3.ListBucketsOutput{Buckets: []types.Bucket{{Name: aws.String("bucket1"), BucketRegion: aws.String("us-east-1")}, {Name: aws.String("bucket2"), BucketRegion: aws.String("us-east-1")}}}, nil
+		},
+		GetBucketTaggingFunc: getBucketTaggingMock,
+		GetObjectLockConfigurationFunc: getObjectLockMock,
+		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+
+			// bucket1 never had versioning and bucket2 was suspended
+			if *params.Bucket == "bucket2" {
+				return &s3.GetBucketVersioningOutput{Status: types.BucketVersioningStatusSuspended}, nil
+			}
+			return &s3.GetBucketVersioningOutput{}, nil
+		},
+		PutBucketVersioningFunc: func(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
+			return &s3.PutBucketVersioningOutput{}, nil
+		},
+	}
+
+	b := Bucket{Client: mockedS3ActionsApi, Suspensions: map[string]Suspension{bucket: suspension}}
+	b.Config = Config{Concurrency: 2, RegionConcurrency: 2, SuspendedPolicy: policyAlertOnly, DisabledPolicy: policyRemediate}
+	result, _ := b.Dispatch()
+
+	assert.Equal(t, actionEnableVersioning, result[0].Action)
+	assert.Assert(t, result[0].Suspension == nil)
+	assert.Equal(t, actionAlert, result[1].Action)
+	assert.Equal(t, findingSuspended, result[1].Suspension.Finding)
+	assert.Equal(t, "arn:aws:sts::111122223333:assumed-role/admin/jdoe", result[1].Suspension.SuspendedBy)
+	assert.Equal(t, "2021-06-25T18:43:47Z", result[1].Suspension.SuspendedAt)
+	assert.Equal(t, 1, len(mockedS3ActionsApi.PutBucketVersioningCalls()))
+	assert.Equal(t, "bucket1", *mockedS3ActionsApi.PutBucketVersioningCalls()[0].Params.Bucket)
+
+	// the finding is still raised when suspended buckets are ignored
+	b = Bucket{Client: mockedS3ActionsApi, Config: Config{SuspendedPolicy: policyIgnore, DisabledPolicy: policyIgnore}}
+	result, _ = b.Dispatch()
+	assert.Equal(t, actionNone, result[1].Action)
+	assert.Equal(t, findingSuspended, result[1].Suspension.Finding)
+	assert.Equal(t, "", result[1].Suspension.SuspendedBy)
+	assert.Equal(t, 1, len(mockedS3ActionsApi.PutBucketVersioningCalls()))
+}
+
+func TestBucketExclusion(t *testing.T) {
+	/*
+	This test is used to test the exclusions of the 'Dispatch' method.
+
+	This will assert that buckets excluded by the exempt tag, the name pattern
+	and the allowlist are reported with the reason and left unchanged, and
+	that the owner tags are recorded. A bucket whose tags cannot be read still
+	gets versioning, and the allowlist still applies without the tags.
+	*/
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		ListBucketsFunc: func(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+			var buckets []types.Bucket
+			for _, name := range []string{"tagged-bucket", "cdk-hnb659fds-assets", "scratch-bucket", "app-bucket", "denied-bucket"} {
+				buckets = append(buckets, types.Bucket{Name: aws.String(name), BucketRegion: aws.String("us-east-1")})
+			}
+			return &s3.ListBucketsOutput{Buckets: buckets}, nil
+		},
+		GetObjectLockConfigurationFunc: getObjectLockMock,
+		GetBucketTaggingFunc: func(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+			switch *params.Bucket {
+			case "scratch-bucket", "denied-bucket":
+				return nil, &smithy.GenericAPIError{Code: "AccessDenied"}
+			case "tagged-bucket":
+			default:
+				return getBucketTaggingMock(ctx, params)
+			}
+
+			var s3Output s3.GetBucketTaggingOutput
+			// read a json file with the exempt tag and an owner tag
+			data, _ := ioutil.ReadFile("test_data/get-bucket-tagging-data.json")
+			json.Unmarshal(data, &s3Output);
+			return &s3Output, nil
+		},
+		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+			return &s3.GetBucketVersioningOutput{}, nil
+		},
+		PutBucketVersioningFunc: func(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
+			return &s3.PutBucketVersioningOutput{}, nil
+		},
+	}
+
+	cfg := Config{
+		ExemptTag:      defaultExemptTag,
+		ExcludePattern: regexp.MustCompile(`^cdk-[a-z0-9]+-assets`),
+		Allowlist:      map[string]bool{"scratch-bucket": true},
+		OwnerTags:      splitList(defaultOwnerTags),
+	}
+	b := Bucket{Client: mockedS3ActionsApi, Config: cfg}
+	result, _ := b.Dispatch()
+
+	assert.Equal(t, excludedTag, result[0].Excluded)
+	assert.DeepEqual(t, map[string]string{"team": "data-platform"}, result[0].OwnerTags)
+	assert.Equal(t, excludedPattern, result[1].Excluded)
+	assert.Equal(t, excludedAllowlist, result[2].Excluded)
+	assert.Assert(t, result[2].TagError != "")
+	assert.Equal(t, "", result[3].Excluded)
+	for _, status := range result[:3] {
+		assert.Equal(t, actionNone, status.Action)
+	}
+
+	assert.Equal(t, actionEnableVersioning, result[4].Action)
+	assert.Equal(t, "", result[4].Error)
+	assert.Assert(t, result[4].TagError != "")
+
+	calls := mockedS3ActionsApi.PutBucketVersioningCalls()
+	assert.Equal(t, 2, len(calls))
+	assert.Equal(t, "app-bucket", *calls[0].Params.Bucket)
+	assert.Equal(t, "denied-bucket", *calls[1].Params.Bucket)
+}
+
+func TestNoncurrentLifecycle(t *testing.T) {
+	/*
+	This test is used to test the functionality of the 'ensureLifecycle' method.
+
+	This will assert that the rule is added after the existing rules, which are
+	written back unchanged, that legacy prefix rules are written back in the
+	filter form, and that nothing is written when the noncurrent versions of the
+	whole bucket already expire or the rule of this Lambda was turned off.
+	*/
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		GetBucketLifecycleConfigurationFunc: func(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+			switch *params.Bucket {
+			case "empty-bucket":
+				return nil, &smithy.GenericAPIError{Code: "NoSuchLifecycleConfiguration"}
+			case "covered-bucket":
+				return &s3.GetBucketLifecycleConfigurationOutput{Rules: []types.LifecycleRule{{
+					Status: types.ExpirationStatusEnabled,
+					NoncurrentVersionExpiration: &types.NoncurrentVersionExpiration{NoncurrentDays: aws.Int32(30)},
+				}}}, nil
+			case "disabled-bucket":
+				return &s3.GetBucketLifecycleConfigurationOutput{Rules: []types.LifecycleRule{{
+					ID: aws.String(lifecycleRuleId),
+					Status: types.ExpirationStatusDisabled,
+					NoncurrentVersionExpiration: &types.NoncurrentVersionExpiration{NoncurrentDays: aws.Int32(30)},
+				}}}, nil
+			case "legacy-bucket":
+				var s3Output s3.GetBucketLifecycleConfigurationOutput
+				// read a json file with a rule in the legacy prefix form
+				data, _ := ioutil.ReadFile("test_data/get-bucket-lifecycle-legacy-data.json")
+				json.Unmarshal(data, &s3Output);
+				return &s3Output, nil
+			}
+
+			var s3Output s3.GetBucketLifecycleConfigurationOutput
+			// read a json file with a rule for a prefix only
+			data, _ := ioutil.ReadFile("test_data/get-bucket-lifecycle-data.json")
+			json.Unmarshal(data, &s3Output);
+			return &s3Output, nil
+		},
+		PutBucketLifecycleConfigurationFunc: func(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+			return &s3.PutBucketLifecycleConfigurationOutput{}, nil
+		},
+	}
+
+	b := Bucket{Client: mockedS3ActionsApi, Config: Config{NoncurrentDays: 60, LifecycleTag: defaultLifecycleTag}}
+
+	result, err := b.ensureLifecycle("logs-bucket")
+	assert.NilError(t, err)
+	assert.Equal(t, lifecycleAdded, result)
+	rules := mockedS3ActionsApi.PutBucketLifecycleConfigurationCalls()[0].Params.LifecycleConfiguration.Rules
+	assert.Equal(t, 2, len(rules))
+	assert.Equal(t, "expire-logs", *rules[0].ID)
+	assert.Equal(t, int32(7), *rules[0].NoncurrentVersionExpiration.NoncurrentDays)
+	assert.Equal(t, lifecycleRuleId, *rules[1].ID)
+	assert.Equal(t, int32(60), *rules[1].NoncurrentVersionExpiration.NoncurrentDays)
+
+	result, err = b.ensureLifecycle("empty-bucket")
+	assert.NilError(t, err)
+	assert.Equal(t, lifecycleAdded, result)
+	assert.Equal(t, 1, len(mockedS3ActionsApi.PutBucketLifecycleConfigurationCalls()[1].Params.LifecycleConfiguration.Rules))
+
+	result, err = b.ensureLifecycle("covered-bucket")
+	assert.NilError(t, err)
+	assert.Equal(t, lifecycleExists, result)
+	assert.Equal(t, 2, len(mockedS3ActionsApi.PutBucketLifecycleConfigurationCalls()))
+
+	result, err = b.ensureLifecycle("disabled-bucket")
+	assert.NilError(t, err)
+	assert.Equal(t, lifecycleDisabled, result)
+	assert.Equal(t, 2, len(mockedS3ActionsApi.PutBucketLifecycleConfigurationCalls()))
+
+	result, err = b.ensureLifecycle("legacy-bucket")
+	assert.NilError(t, err)
+	assert.Equal(t, lifecycleAdded, result)
+	rules = mockedS3ActionsApi.PutBucketLifecycleConfigurationCalls()[2].Params.LifecycleConfiguration.Rules
+	assert.Equal(t, 2, len(rules))
+	assert.Assert(t, rules[0].Prefix == nil)
+	assert.Equal(t, "tmp/", *rules[0].Filter.Prefix)
+	assert.Equal(t, int32(1), *rules[0].Expiration.Days)
+
+	// the rule is only wanted for buckets that opt in, unless it is turned on for all
+	assert.Equal(t, true, wantsLifecycle(map[string]string{defaultLifecycleTag: "true"}, b.Config))
+	assert.Equal(t, false, wantsLifecycle(map[string]string{}, b.Config))
+	b.Config.NoncurrentLifecycle = true
+	assert.Equal(t, true, wantsLifecycle(map[string]string{}, b.Config))
+}
+
+func TestImmutablePosture(t *testing.T) {
+	/*
+	This test is used to test the posture reporting of the 'checkBucketVersion' method.
+
+	This will assert that the MFA Delete and Object Lock retention are reported,
+	and that buckets tagged as immutable are flagged for each protection they lack.
+	An Object Lock configuration that cannot be read is reported as an error and
+	not flagged as missing.
+	*/
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		GetBucketTaggingFunc: func(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+			if *params.Bucket == "plain-bucket" {
+				return getBucketTaggingMock(ctx, params)
+			}
+			return &s3.GetBucketTaggingOutput{TagSet: []types.Tag{{Key: aws.String(defaultImmutableTag), Value: aws.String("true")}}}, nil
+		},
+		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+			return &s3.GetBucketVersioningOutput{Status: types.BucketVersioningStatusEnabled, MFADelete: types.MFADeleteStatusDisabled}, nil
+		},
+		GetObjectLockConfigurationFunc: func(ctx context.Context, params *s3.GetObjectLockConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetObjectLockConfigurationOutput, error) {
+			switch *params.Bucket {
+			case "denied-bucket":
+				return nil, &smithy.GenericAPIError{Code: "AccessDenied"}
+			case "locked-bucket":
+			default:
+				return getObjectLockMock(ctx, params)
+			}
+
+			var s3Output s3.GetObjectLockConfigurationOutput
+			// read a json file with a governance retention of 30 days
+			data, _ := ioutil.ReadFile("test_data/get-object-lock-data.json")
+			json.Unmarshal(data, &s3Output);
+			return &s3Output, nil
+		},
+	}
+
+	b := Bucket{Client: mockedS3ActionsApi, Config: Config{ImmutableTag: defaultImmutableTag}}
+	b.BucketList = []string{"locked-bucket", "unlocked-bucket", "plain-bucket", "denied-bucket"}
+	result := b.checkBucketVersion()
+
+	assert.Equal(t, "Enabled", result[0].ObjectLock.Status)
+	assert.Equal(t, "GOVERNANCE", result[0].ObjectLock.RetentionMode)
+	assert.Equal(t, int32(30), result[0].ObjectLock.RetentionDays)
+	assert.DeepEqual(t, []string{findingNoMFADelete}, result[0].Immutable)
+	assert.DeepEqual(t, []string{findingNoObjectLock, findingNoMFADelete}, result[1].Immutable)
+	assert.Equal(t, statusDisabled, result[2].ObjectLock.Status)
+	assert.Assert(t, result[2].Immutable == nil)
+
+	assert.Equal(t, objectLockUnknown, result[3].ObjectLock.Status)
+	assert.Assert(t, result[3].ObjectLock.Error != "")
+	assert.DeepEqual(t, []string{findingNoMFADelete}, result[3].Immutable)
+	assert.Equal(t, "", result[3].Error)
+}
+
+func TestEventBucket(t *testing.T) {
+	/*
+	This test is used to test the event handling of the 'DispatchBucket' method.
+
+	This will assert that the bucket is found in 'CreateBucket' and suspending
+	'PutBucketVersioning' events, and that only that bucket is checked and
+	remediated, through the client for the region in the event.
+	*/
+	for _, file := range []string{"event-create-bucket.json", "event-suspend-versioning.json"} {
+		var event VersioningEvent
+		data, _ := ioutil.ReadFile("test_data/" + file)
+		json.Unmarshal(data, &event);
+
+		detail, err := readEvent(event)
+		assert.NilError(t, err)
+		assert.Assert(t, eventBucket(detail) != "")
+	}
+
+	// enabling versioning, failed calls and other events leave nothing to do
+	enabled := &cloudTrailDetail{EventName: "PutBucketVersioning"}
+	enabled.RequestParameters.BucketName = "bucket1"
+	enabled.RequestParameters.VersioningConfiguration.Status = statusEnabled
+	assert.Equal(t, "", eventBucket(enabled))
+	assert.Equal(t, "", eventBucket(&cloudTrailDetail{EventName: "CreateBucket", ErrorCode: "BucketAlreadyExists"}))
+	assert.Equal(t, "", eventBucket(&cloudTrailDetail{EventName: "DeleteBucket"}))
+
+	// the mocks have no 'ListBuckets', so a full sweep would panic the test
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		GetBucketTaggingFunc: getBucketTaggingMock,
+		GetObjectLockConfigurationFunc: getObjectLockMock,
+		GetBucketVersioningFunc: func(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+			return &s3.GetBucketVersioningOutput{}, nil
+		},
+		PutBucketVersioningFunc: func(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
+			return &s3.PutBucketVersioningOutput{}, nil
+		},
+	}
+
+	var regions []string
+	b := Bucket{Client: &S3ActionsApiMock{}, Regional: func(region string) S3ActionsApi {
+		regions = append(regions, region)
+		return mockedS3ActionsApi
+	}}
+	result := b.DispatchBucket("new-bucket", "eu-west-1")
+
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "new-bucket", result[0].Name)
+	assert.Equal(t, "eu-west-1", result[0].Region)
+	assert.Equal(t, actionEnableVersioning, result[0].Action)
+	assert.Equal(t, 1, len(mockedS3ActionsApi.PutBucketVersioningCalls()))
+	assert.Equal(t, "eu-west-1", regions[0])
+}
+
+func TestPublicAccessBlock(t *testing.T) {
+	/*
+	This test is used to test the functionality of the 'enforcePublicAccessBlock' method.
+
+	This will assert that all four flags are set on a bucket without the settings,
+	that a bucket with every flag set and an exempt website bucket are left
+	unchanged, and that a bucket with unknown tags is skipped.
+	*/
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		GetPublicAccessBlockFunc: func(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error) {
+			if *params.Bucket == "blocked-bucket" {
+				return &s3.GetPublicAccessBlockOutput{PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration{
+					BlockPublicAcls: aws.Bool(true), IgnorePublicAcls: aws.Bool(true), BlockPublicPolicy: aws.Bool(true), RestrictPublicBuckets: aws.Bool(true),
+				}}, nil
+			}
+			return nil, &smithy.GenericAPIError{Code: "NoSuchPublicAccessBlockConfiguration"}
+		},
+		PutPublicAccessBlockFunc: func(ctx context.Context, params *s3.PutPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.PutPublicAccessBlockOutput, error) {
+			return &s3.PutPublicAccessBlockOutput{}, nil
+		},
+	}
+
+	statuses := []BucketStatus{
+		{Name: "open-bucket", tags: map[string]string{}},
+		{Name: "blocked-bucket", tags: map[string]string{}},
+		{Name: "website-bucket", tags: map[string]string{defaultPublicAccessTag: "true"}},
+		{Name: "unknown-bucket", Error: "AccessDenied"},
+	}
+
+	b := Bucket{Client: mockedS3ActionsApi, Config: Config{PublicAccessExemptTag: defaultPublicAccessTag}}
+	b.enforcePublicAccessBlock(statuses)
+
+	assert.Equal(t, publicAccessBlocked, statuses[0].PublicAccess.Action)
+	assert.Equal(t, true, statuses[0].PublicAccess.allBlocked())
+	assert.Equal(t, publicAccessNone, statuses[1].PublicAccess.Action)
+	assert.Equal(t, publicAccessExempt, statuses[2].PublicAccess.Action)
+	assert.Equal(t, false, statuses[2].PublicAccess.BlockPublicPolicy)
+	assert.Assert(t, statuses[3].PublicAccess == nil)
+
+	calls := mockedS3ActionsApi.PutPublicAccessBlockCalls()
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, "open-bucket", *calls[0].Params.Bucket)
+	assert.Equal(t, true, *calls[0].Params.PublicAccessBlockConfiguration.RestrictPublicBuckets)
+}
+
+func TestDefaultEncryption(t *testing.T) {
+	/*
+	This test is used to test the functionality of the 'enforceEncryption' method.
+
+	This will assert that an SSE-S3 bucket and a bucket with an unapproved key get
+	SSE-KMS with the configured key and bucket keys, reported on their record,
+	that a DSSE-KMS bucket keeps DSSE-KMS, and that a bucket with an approved key
+	named by ID is left unchanged. A bucket whose region could not be found is
+	skipped.
+	*/
+	keyArn := "arn:aws:kms:us-east-1:123456789012:key/approved-key"
+	mockedS3ActionsApi := &S3ActionsApiMock{
+		GetBucketEncryptionFunc: func(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+			byDefault := &types.ServerSideEncryptionByDefault{SSEAlgorithm: types.ServerSideEncryptionAes256}
+			switch *params.Bucket {
+			case "approved-bucket":
+				byDefault = &types.ServerSideEncryptionByDefault{SSEAlgorithm: types.ServerSideEncryptionAwsKms, KMSMasterKeyID: aws.String("approved-key")}
+			case "other-key-bucket":
+				byDefault = &types.ServerSideEncryptionByDefault{SSEAlgorithm: types.ServerSideEncryptionAwsKmsDsse, KMSMasterKeyID: aws.String("arn:aws:kms:us-east-1:123456789012:key/other-key")}
+			}
+			return &s3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+				Rules: []types.ServerSideEncryptionRule{{ApplyServerSideEncryptionByDefault: byDefault}},
+			}}, nil
+		},
+		PutBucketEncryptionFunc: func(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error) {
+			return &s3.PutBucketEncryptionOutput{}, nil
+		},
+	}
+
+	statuses := []BucketStatus{
+		{Name: "sse-s3-bucket"},
+		{Name: "approved-bucket"},
+		{Name: "other-key-bucket"},
+		{Name: "unknown-region-bucket", Error: "unable to find bucket region: AccessDenied"},
+	}
+
+	b := Bucket{Client: mockedS3ActionsApi, regionErrors: map[string]error{"unknown-region-bucket": errors.New("AccessDenied")}, Config: Config{
+		RequireSSEKMS: true, ApprovedKMSKeys: map[string]bool{keyArn: true}, EncryptionRemediation: true, EncryptionKeyArn: keyArn,
+	}}
+	b.enforceEncryption(statuses)
+
+	assert.DeepEqual(t, []string{findingKMSRequired}, statuses[0].Encryption.Findings)
+	assert.Equal(t, encryptionApply, statuses[0].Encryption.Action)
+	assert.Equal(t, string(types.ServerSideEncryptionAwsKms), statuses[0].Encryption.Algorithm)
+	assert.Equal(t, keyArn, statuses[0].Encryption.KmsKeyId)
+	assert.Equal(t, true, statuses[0].Encryption.BucketKeyEnabled)
+	assert.Equal(t, 0, len(statuses[1].Encryption.Findings))
+	assert.Equal(t, encryptionNone, statuses[1].Encryption.Action)
+	assert.Equal(t, "approved-key", statuses[1].Encryption.KmsKeyId)
+	assert.Equal(t, false, statuses[1].Encryption.BucketKeyEnabled)
+	assert.DeepEqual(t, []string{findingUnapprovedKey}, statuses[2].Encryption.Findings)
+	assert.Equal(t, string(types.ServerSideEncryptionAwsKmsDsse), statuses[2].Encryption.Algorithm)
+	assert.Equal(t, keyArn, statuses[2].Encryption.KmsKeyId)
+	assert.Assert(t, statuses[3].Encryption == nil)
+	assert.Equal(t, 3, len(mockedS3ActionsApi.GetBucketEncryptionCalls()))
+
+	calls := mockedS3ActionsApi.PutBucketEncryptionCalls()
+	assert.Equal(t, 2, len(calls))
+	for _, call := range calls {
+		rule := call.Params.ServerSideEncryptionConfiguration.Rules[0]
+		algorithm := types.ServerSideEncryptionAwsKms
+		if *call.Params.Bucket == "other-key-bucket" {
+			algorithm = types.ServerSideEncryptionAwsKmsDsse
+		}
+		assert.Equal(t, algorithm, rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+		assert.Equal(t, keyArn, *rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID)
+		assert.Equal(t, true, *rule.BucketKeyEnabled)
+	}
+}
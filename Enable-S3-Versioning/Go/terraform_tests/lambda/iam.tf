@@ -33,6 +33,15 @@ resource "aws_iam_policy" "lambda_policy_for_s3_versioning" {
             "Effect": "Allow",
             "Action": [
                 "s3:GetBucketVersioning",
+                "s3:GetBucketLocation",
+                "s3:GetBucketTagging",
+                "s3:GetLifecycleConfiguration",
+                "s3:PutLifecycleConfiguration",
+                "s3:GetBucketObjectLockConfiguration",
+                "s3:GetBucketPublicAccessBlock",
+                "s3:PutBucketPublicAccessBlock",
+                "s3:GetEncryptionConfiguration",
+                "s3:PutEncryptionConfiguration",
                 "logs:PutLogEvents",
                 "s3:PutBucketVersioning"
             ],
@@ -13,4 +13,30 @@ resource "aws_lambda_function" "versioning_lambda" {
   handler = "main"
   runtime = "go1.x"
 
-}
\ No newline at end of file
+}
+resource "aws_cloudwatch_event_rule" "s3_bucket_events" {
+  name = "${var.lambda_func_name}-bucket-events"
+  event_pattern = <<EOF
+{
+  "source": ["aws.s3"],
+  "detail-type": ["AWS API Call via CloudTrail"],
+  "detail": {
+    "eventSource": ["s3.amazonaws.com"],
+    "eventName": ["CreateBucket", "PutBucketVersioning"]
+  }
+}
+EOF
+}
+
+resource "aws_cloudwatch_event_target" "s3_bucket_events" {
+  rule = aws_cloudwatch_event_rule.s3_bucket_events.name
+  arn = aws_lambda_function.versioning_lambda.arn
+}
+
+resource "aws_lambda_permission" "s3_bucket_events" {
+  statement_id = "AllowExecutionFromEventBridge"
+  action = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.versioning_lambda.function_name
+  principal = "events.amazonaws.com"
+  source_arn = aws_cloudwatch_event_rule.s3_bucket_events.arn
+}